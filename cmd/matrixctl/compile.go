@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// compile writes the binary dictionary index of one language, or of every
+// language whose dictionary file exists
+func compile(env *environment, args []string) error {
+	flags := flag.NewFlagSet("compile", flag.ContinueOnError)
+	lang := flags.String("language", "", "language to compile (default: all with a dictionary)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	names := env.languages.Names()
+	if *lang != "" {
+		names = []string{*lang}
+	}
+	ctx := context.Background()
+	for _, name := range names {
+		pack, err := env.languages.Get(name)
+		if err != nil {
+			return err
+		}
+		if *lang == "" {
+			// The dictionary the service would read, under the base directory
+			if _, err := os.Stat(env.files.Path(pack.ResourceDir, pack.DictionaryFile)); os.IsNotExist(err) {
+				fmt.Printf("%s: no dictionary, skipped\n", pack.Name)
+				continue
+			}
+		}
+
+		meta, path, err := env.words.CompileIndex(ctx, pack.Name)
+		if err != nil {
+			return fmt.Errorf("%s: %w", pack.Name, err)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s: %d entries -> %s (%d bytes)\n", pack.Name, meta.Entries, path, info.Size())
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+
+	"service-matrix-go/internal/core/domain"
+)
+
+// fsck checks, and with -fix repairs, the lists of one language. It fails
+// if problems remain afterwards, so it can gate a deployment.
+func fsck(env *environment, args []string) error {
+	flags := flag.NewFlagSet("fsck", flag.ContinueOnError)
+	lang := flags.String("language", "", "language to check (default: the default language)")
+	fix := flags.Bool("fix", false, "rewrite the lists without the fixable problems")
+	limit := flags.Int("limit", 0, "list at most this many problems (0: all)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	report, err := env.words.Check(context.Background(), domain.FsckRequest{Language: *lang, Fix: *fix, Limit: *limit})
+	if err != nil {
+		return err
+	}
+
+	for _, issue := range report.Issues {
+		fixable := ""
+		if !issue.Fixable {
+			fixable = " (not fixable)"
+		}
+		fmt.Printf("%s:%d: %s %q %s%s\n", issue.List, issue.Line, issue.Kind, issue.Word, issue.Detail, fixable)
+	}
+	if report.Truncated {
+		fmt.Println("...")
+	}
+
+	printCounts(report)
+	if report.Fixed {
+		for _, change := range report.Changes {
+			fmt.Printf("rewrote %s\n", change.List)
+		}
+		// What the fix could not repair
+		if report, err = env.words.Check(context.Background(), domain.FsckRequest{Language: *lang}); err != nil {
+			return err
+		}
+		if len(report.Counts) > 0 {
+			fmt.Println("remaining:")
+			printCounts(report)
+		}
+	}
+	if len(report.Counts) > 0 {
+		return errors.New("problems found")
+	}
+	return nil
+}
+
+func printCounts(report domain.FsckReport) {
+	kinds := make([]string, 0, len(report.Counts))
+	for kind := range report.Counts {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		fmt.Printf("%s: %d\n", kind, report.Counts[kind])
+	}
+}
@@ -0,0 +1,74 @@
+// Command matrixctl runs maintenance tasks against the service's data. It
+// reads the same CONFIG_FILE and environment variables as the server.
+//
+// Usage:
+//
+//	matrixctl compile [-language ru]
+//	matrixctl fsck [-language ru] [-fix] [-limit n]
+//	matrixctl seal file...
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"service-matrix-go/internal/config"
+	"service-matrix-go/internal/core/language"
+	"service-matrix-go/internal/core/services"
+	"service-matrix-go/internal/infrastructure/storage"
+	"service-matrix-go/internal/infrastructure/wordstore"
+)
+
+// commands maps each subcommand to its implementation
+var commands = map[string]func(env *environment, args []string) error{
+	"compile": compile,
+	"fsck":    fsck,
+	"seal":    seal,
+}
+
+// environment is what the commands share, set up the way the server does it
+type environment struct {
+	languages *language.Registry
+	files     *storage.FileHelper
+	words     *services.WordService
+}
+
+func main() {
+	if len(os.Args) < 2 || commands[os.Args[1]] == nil {
+		fmt.Fprintln(os.Stderr, "usage: matrixctl compile [-language name]")
+		fmt.Fprintln(os.Stderr, "       matrixctl fsck [-language name] [-fix] [-limit n]")
+		fmt.Fprintln(os.Stderr, "       matrixctl seal file...")
+		os.Exit(2)
+	}
+
+	env, err := newEnvironment()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "matrixctl:", err)
+		os.Exit(1)
+	}
+	if err := commands[os.Args[1]](env, os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "matrixctl %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+func newEnvironment() (*environment, error) {
+	cfg, err := config.Load("matrixctl", nil, os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+	languages := language.NewRegistryWithLayout(cfg.Layout())
+	if err := languages.LoadDir(cfg.LanguagesPath()); err != nil {
+		return nil, err
+	}
+	fileHelper := storage.NewFileHelper(cfg.BaseDir)
+	wordRepo, _, err := wordstore.Open(cfg, fileHelper, languages)
+	if err != nil {
+		return nil, err
+	}
+	return &environment{
+		languages: languages,
+		files:     fileHelper,
+		words:     services.NewWordService(fileHelper, wordRepo, languages),
+	}, nil
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"path/filepath"
+)
+
+// seal accepts the current contents of files edited by hand, recording
+// their checksums so the server reads them again. Paths are relative to the
+// base directory, like data/include.txt.
+func seal(env *environment, args []string) error {
+	flags := flag.NewFlagSet("seal", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() == 0 {
+		return errors.New("name the files to seal")
+	}
+
+	for _, path := range flags.Args() {
+		if err := env.files.Seal(filepath.Dir(path), filepath.Base(path)); err != nil {
+			return err
+		}
+		fmt.Printf("sealed %s\n", path)
+	}
+	return nil
+}
@@ -1,55 +1,206 @@
 package main
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
 
 	"service-matrix-go/internal/api/handlers"
+	"service-matrix-go/internal/auth"
+	"service-matrix-go/internal/config"
+	"service-matrix-go/internal/core/language"
 	"service-matrix-go/internal/core/services"
+	"service-matrix-go/internal/infrastructure/dictindex"
+	"service-matrix-go/internal/infrastructure/replication"
 	"service-matrix-go/internal/infrastructure/storage"
+	"service-matrix-go/internal/infrastructure/wordstore"
+	"service-matrix-go/internal/logging"
+	"service-matrix-go/internal/metrics"
 )
 
 func main() {
-	// Initialize dependencies
-	cwd, err := os.Getwd()
+	cfg, err := config.Load(os.Args[0], os.Args[1:], os.Getenv)
 	if err != nil {
-		log.Fatal(err)
+		// The logger isn't set up yet, and each problem is on its own line
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
 	}
+	logger, _ := logging.New(os.Stderr, cfg.Log.Level, cfg.Log.Format)
+	slog.SetDefault(logger)
 
-	fileHelper := storage.NewFileHelper(cwd)
-	wordService := services.NewWordService(fileHelper)
-	httpHandlers := handlers.NewHTTPHandlers(wordService)
+	// Built-in packs can be overridden or extended by <resourceDir>/languages/<name>.json
+	languages := language.NewRegistryWithLayout(cfg.Layout())
+	if err := languages.LoadDir(cfg.LanguagesPath()); err != nil {
+		fatal("loading language packs", err)
+	}
+
+	fileHelper := storage.NewFileHelper(cfg.BaseDir)
+	wordRepo, submissionRepo, err := wordstore.Open(cfg, fileHelper, languages)
+	if err != nil {
+		fatal("opening the word store", err)
+	}
+	wordService := services.NewWordService(fileHelper, wordRepo, languages)
+	registry := metrics.NewRegistry()
+	registry.NewGaugeFunc("go_goroutines", "Number of goroutines that currently exist.", func() float64 {
+		return float64(runtime.NumGoroutine())
+	})
+	wordService.UseMetrics(services.NewMetrics(registry))
+	wordService.UseSearchSettings(services.SearchSettings{
+		MaxRows:   cfg.Limits.MaxBoardRows,
+		MaxCols:   cfg.Limits.MaxBoardCols,
+		MaxWords:  cfg.Search.MaxWords,
+		SortBy:    cfg.Search.SortBy,
+		MaxRarity: cfg.Search.MaxRarity,
+	})
+
+	// Compiled dictionaries (matrixctl compile) are memory-mapped by default
+	indexMode, _ := dictindex.ParseMode(cfg.DictionaryIndex)
+	wordService.UseDictionaryIndex(indexMode)
+
+	moderationService := services.NewModerationService(wordService, submissionRepo, cfg.Moderation.AutoApproveVotes)
+
+	snapshotStore := storage.NewFileSnapshotStore(fileHelper, filepath.Join(cfg.DataDir, cfg.Files.Snapshots))
+	snapshotService := services.NewSnapshotService(wordService, snapshotStore, cfg.Snapshots.Retention, cfg.Snapshots.OnMutation)
+	if err := snapshotService.EnsureBaseline(context.Background()); err != nil {
+		fatal("taking the baseline snapshot", err)
+	}
+	wordService.Observe(snapshotService)
+
+	// Every mutation is appended to the audit log whatever the word store
+	auditService := services.NewAuditService(storage.NewFileAuditLog(fileHelper, cfg.DataDir, cfg.Files.Audit), languages)
+	wordService.Observe(auditService)
+	wordService.UseAudit(auditService)
+
+	changeFeed := services.NewChangeFeedService(wordService, storage.NewFileChangeLog(fileHelper, cfg.DataDir, cfg.Files.Changes))
+	if err := changeFeed.EnsureBaseline(context.Background()); err != nil {
+		fatal("starting the changes feed", err)
+	}
+	wordService.Observe(changeFeed)
+
+	// SIGTERM or SIGINT starts a graceful shutdown
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	// Background work that must finish before the process exits
+	var background sync.WaitGroup
+
+	// A leader makes this instance a follower of another one's feed
+	if leader := cfg.Follower.Leader; leader != "" {
+		interval := cfg.Follower.Interval.Duration
+		wordService.FollowLeader(cfg.Redacted().Follower.Leader)
+		follower := services.NewFollower(wordService, replication.NewLeaderClient(leader, cfg.Follower.Key, 30*time.Second), leader, interval, cfg.DataDir, cfg.Files.FollowerState)
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			follower.Run(ctx)
+		}()
+		slog.Info("following leader", "leader", cfg.Redacted().Follower.Leader, "interval", interval)
+	}
+
+	// /readyz fails until the dictionaries are loaded
+	go func() {
+		if err := wordService.Preload(ctx); err != nil {
+			slog.Error("preloading dictionaries", "err", err)
+		}
+	}()
+
+	httpHandlers := handlers.NewHTTPHandlers(wordService, moderationService, snapshotService, auditService, changeFeed)
+
+	keys := cfg.Auth.Keys
+	if path := cfg.KeysPath(); path != "" {
+		fileKeys, err := auth.LoadKeysFile(path)
+		if err != nil {
+			fatal("loading API keys", err)
+		}
+		keys = append(keys, fileKeys...)
+	}
+	keyring, err := auth.NewKeyring(keys)
+	if err != nil {
+		fatal("loading API keys", err)
+	}
+	if keyring.Len() == 0 {
+		slog.Warn("no API keys configured, admin routes are unavailable", "anonymousRole", cfg.Auth.AnonymousRole)
+	}
+	anonymousRole, _ := auth.ParseRole(cfg.Auth.AnonymousRole)
 
 	// Router setup
 	mux := http.NewServeMux()
+	authorizer := handlers.NewAuthorizer(keyring, anonymousRole)
+	limiter := handlers.NewRateLimiter(cfg.RateLimits.Search, cfg.RateLimits.Mutation, cfg.RateLimits.MaxConcurrentSearches)
+	handlers.Register(mux, handlers.Routes(httpHandlers, cfg, registry),
+		handlers.NewHTTPMetrics(registry).Wrap,
+		handlers.LogRequests,
+		authorizer.Wrap,
+		limiter.Wrap,
+		handlers.Recover,
+	)
 
-	// Register endpoints (matching C# routes approx)
-	// C# Route: [Route("[controller]")] -> /Words
-	// Actions: [HttpPost("Search")] -> /Words/Search
+	// Add CORS middleware if needed (found in C# Program.cs)
+	handler := corsMiddleware(cfg.CORS.Origins, handlers.RequestInfo(authorizer.Authenticate(handlers.LimitBody(cfg.Limits.MaxRequestBytes, mux))))
 
-	mux.HandleFunc("/Words/Search", httpHandlers.Search)
-	mux.HandleFunc("/Words/Update", httpHandlers.Update)
-	mux.HandleFunc("/Words/List", httpHandlers.GetList)
-	mux.HandleFunc("/Words/Merge", httpHandlers.MergeWords)
-	mux.HandleFunc("/Words/CleanMerge", httpHandlers.CleanMerge)
-	mux.HandleFunc("/Words/LookupWord", httpHandlers.LookupWord)
+	server := &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.Server.ReadTimeout.Duration,
+		ReadTimeout:       cfg.Server.ReadTimeout.Duration,
+		WriteTimeout:      cfg.Server.WriteTimeout.Duration,
+		IdleTimeout:       cfg.Server.IdleTimeout.Duration,
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("server starting", "addr", cfg.Addr)
+		serveErr <- server.ListenAndServe()
+	}()
 
-	// Add CORS middleware if needed (found in C# Program.cs)
-	handler := corsMiddleware(mux)
+	select {
+	case err := <-serveErr:
+		fatal("serving", err)
+	case <-ctx.Done():
+	}
 
-	port := ":8080"
-	log.Printf("Server starting on port %s", port)
-	if err := http.ListenAndServe(port, handler); err != nil {
-		log.Fatal(err)
+	// Stop taking new connections and let in-flight searches and writes finish
+	slog.Info("shutting down", "timeout", cfg.Server.ShutdownTimeout.Duration)
+	httpHandlers.Drain()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout.Duration)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("requests still running at the shutdown deadline", "err", err)
 	}
+	background.Wait()
+	slog.Info("server stopped")
+}
+
+// fatal logs err and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "err", err)
+	os.Exit(1)
 }
 
-func corsMiddleware(next http.Handler) http.Handler {
+// corsMiddleware lets browsers on the allowed origins call the API; "*"
+// allows any origin
+func corsMiddleware(origins []string, next http.Handler) http.Handler {
+	allowAny := slices.Contains(origins, "*")
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
+		origin := r.Header.Get("Origin")
+		switch {
+		case allowAny:
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		case origin != "" && slices.Contains(origins, origin):
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+		w.Header().Add("Vary", "Origin")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS, PUT, DELETE")
-		w.Header().Set("Access-Control-Allow-Headers", "*")
+		// Spelled out: a "*" never covers Authorization
+		w.Header().Set("Access-Control-Allow-Headers", "Authorization, X-API-Key, Content-Type, X-Request-ID")
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
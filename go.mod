@@ -1,3 +1,5 @@
 module service-matrix-go
 
-go 1.21
+go 1.22
+
+require golang.org/x/text v0.21.0
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"service-matrix-go/internal/auth"
+	"service-matrix-go/internal/core/requestinfo"
+)
+
+// apiKeyHeader carries the caller's API key; "Authorization: Bearer" works too
+const apiKeyHeader = "X-API-Key"
+
+// Authorizer checks callers' API keys against the roles routes need
+type Authorizer struct {
+	keys      *auth.Keyring
+	anonymous auth.Role
+}
+
+// NewAuthorizer grants callers without a key the anonymous role. An empty
+// keyring changes nothing, so routes above that role stay closed.
+func NewAuthorizer(keys *auth.Keyring, anonymous auth.Role) *Authorizer {
+	return &Authorizer{keys: keys, anonymous: anonymous}
+}
+
+// Authenticate identifies callers by their API key, recording the key name
+// in the request info. An unknown key is refused outright.
+func (a *Authorizer) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		secret := apiKey(r)
+		if secret == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		key, ok := a.keys.Lookup(secret)
+		if !ok {
+			slog.WarnContext(r.Context(), "unknown API key", "method", r.Method, "path", r.URL.Path)
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, r, http.StatusUnauthorized, codeUnauthorized, "", "unknown API key")
+			return
+		}
+		info := requestinfo.FromContext(r.Context())
+		info.Key = key.Name
+		next.ServeHTTP(w, r.WithContext(requestinfo.WithInfo(r.Context(), info)))
+	})
+}
+
+// Wrap serves the route only to callers whose role covers the method's:
+// route.Read for GET and HEAD, route.Write for the rest
+func (a *Authorizer) Wrap(route Route, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		need := route.Write
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			need = route.Read
+		}
+
+		role := a.anonymous
+		info := requestinfo.FromContext(r.Context())
+		if key, ok := a.keys.Named(info.Key); ok {
+			role = key.Role
+		}
+		if !role.Allows(need) {
+			if info.Key == "" {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				writeError(w, r, http.StatusUnauthorized, codeUnauthorized, "", "an API key is required")
+				return
+			}
+			slog.WarnContext(r.Context(), "API key lacks the role", "method", r.Method, "route", route.Pattern, "role", role, "needs", need)
+			writeError(w, r, http.StatusForbidden, codeForbidden, "", "this API key may not "+r.Method+" "+r.URL.Path)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// apiKey returns the key from X-API-Key or an Authorization bearer token
+func apiKey(r *http.Request) string {
+	if key := r.Header.Get(apiKeyHeader); key != "" {
+		return key
+	}
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"service-matrix-go/internal/config"
+)
+
+// DebugConfig serves the running configuration with its secrets redacted
+func DebugConfig(cfg config.Config) http.HandlerFunc {
+	redacted := cfg.Redacted()
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, r, http.MethodGet)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(redacted)
+	}
+}
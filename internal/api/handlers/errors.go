@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"service-matrix-go/internal/core/domain"
+	"service-matrix-go/internal/core/language"
+	"service-matrix-go/internal/core/requestinfo"
+)
+
+// Error codes clients can branch on
+const (
+	codeInvalidRequest   = "invalid_request"
+	codeUnknownLanguage  = "unknown_language"
+	codeUnauthorized     = "unauthorized"
+	codeForbidden        = "forbidden"
+	codeNotFound         = "not_found"
+	codeMethodNotAllowed = "method_not_allowed"
+	codeConflict         = "conflict"
+	codeTooLarge         = "request_too_large"
+	codeRateLimited      = "rate_limited"
+	codeInternal         = "internal"
+)
+
+// writeError replies with the JSON error envelope, tagged with the request ID
+func writeError(w http.ResponseWriter, r *http.Request, status int, code, field, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(domain.ErrorResponse{Error: domain.ErrorBody{
+		Code:      code,
+		Message:   message,
+		Field:     field,
+		RequestID: requestinfo.FromContext(r.Context()).RequestID,
+	}})
+}
+
+// writeServiceError maps service errors to HTTP status codes. Unexpected
+// errors are logged and reported without their details.
+func writeServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	var fieldErr *domain.FieldError
+	switch {
+	case errors.As(err, &fieldErr):
+		writeError(w, r, http.StatusBadRequest, codeInvalidRequest, fieldErr.Field, fieldErr.Message)
+	case errors.Is(err, language.ErrUnknownLanguage):
+		writeError(w, r, http.StatusBadRequest, codeUnknownLanguage, "language", err.Error())
+	case errors.Is(err, domain.ErrInvalidRequest):
+		writeError(w, r, http.StatusBadRequest, codeInvalidRequest, "", err.Error())
+	case errors.Is(err, domain.ErrNotFound):
+		writeError(w, r, http.StatusNotFound, codeNotFound, "", err.Error())
+	case errors.Is(err, domain.ErrConflict):
+		writeError(w, r, http.StatusConflict, codeConflict, "", err.Error())
+	default:
+		slog.ErrorContext(r.Context(), "request failed", "method", r.Method, "path", r.URL.Path, "err", err)
+		writeError(w, r, http.StatusInternalServerError, codeInternal, "", "internal error")
+	}
+}
+
+// writeInvalidParam reports a bad query parameter
+func writeInvalidParam(w http.ResponseWriter, r *http.Request, param, message string) {
+	writeError(w, r, http.StatusBadRequest, codeInvalidRequest, param, message)
+}
+
+// methodNotAllowed replies 405, listing the methods the endpoint takes
+func methodNotAllowed(w http.ResponseWriter, r *http.Request, allowed ...string) {
+	for _, method := range allowed {
+		w.Header().Add("Allow", method)
+	}
+	writeError(w, r, http.StatusMethodNotAllowed, codeMethodNotAllowed, "", r.Method+" is not allowed on "+r.URL.Path)
+}
+
+// notFound is the reply for paths no route matches
+func notFound(w http.ResponseWriter, r *http.Request) {
+	writeError(w, r, http.StatusNotFound, codeNotFound, "", "no endpoint at "+r.URL.Path)
+}
+
+// decodeJSON decodes the request body into v. A missing, malformed or
+// oversized body has been answered when it returns false.
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err == io.EOF {
+		writeError(w, r, http.StatusBadRequest, codeInvalidRequest, "", "request body is empty")
+		return false
+	}
+	return decodeOK(w, r, err)
+}
+
+// decodeOptionalJSON is decodeJSON for endpoints where the body may be left out
+func decodeOptionalJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	err := json.NewDecoder(r.Body).Decode(v)
+	if err == io.EOF {
+		return true
+	}
+	return decodeOK(w, r, err)
+}
+
+func decodeOK(w http.ResponseWriter, r *http.Request, err error) bool {
+	var (
+		tooLarge  *http.MaxBytesError
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+	)
+	switch {
+	case err == nil:
+		return true
+	case errors.As(err, &tooLarge):
+		writeError(w, r, http.StatusRequestEntityTooLarge, codeTooLarge, "", fmt.Sprintf("request body is larger than %d bytes", tooLarge.Limit))
+	case errors.As(err, &syntaxErr):
+		writeError(w, r, http.StatusBadRequest, codeInvalidRequest, "", fmt.Sprintf("request body is not valid JSON at byte %d", syntaxErr.Offset))
+	case errors.As(err, &typeErr):
+		name := typeErr.Field
+		if name == "" {
+			name = "request body"
+		}
+		writeError(w, r, http.StatusBadRequest, codeInvalidRequest, typeErr.Field, fmt.Sprintf("%s must be a %s, not a JSON %s", name, typeErr.Type, typeErr.Value))
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		writeError(w, r, http.StatusBadRequest, codeInvalidRequest, "", "request body is cut short")
+	default:
+		writeError(w, r, http.StatusBadRequest, codeInvalidRequest, "", "request body: "+err.Error())
+	}
+	return false
+}
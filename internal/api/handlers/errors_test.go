@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"service-matrix-go/internal/config"
+	"service-matrix-go/internal/core/language"
+	"service-matrix-go/internal/core/services"
+	"service-matrix-go/internal/infrastructure/memory"
+	"service-matrix-go/internal/infrastructure/storage"
+	"service-matrix-go/internal/metrics"
+)
+
+// maxTestBody is the body size limitServer accepts
+const maxTestBody = 512
+
+// limitServer routes every endpoint like the server does, on boards of at
+// most 3×3 and bodies of at most maxTestBody bytes
+func limitServer(t *testing.T) http.Handler {
+	t.Helper()
+	service, fh := dictService(t, "кот\n")
+	service.UseSearchSettings(services.SearchSettings{MaxRows: 3, MaxCols: 3, MaxWords: 10})
+	audit := services.NewAuditService(storage.NewFileAuditLog(fh, "data", "audit.jsonl"), language.NewRegistry())
+	changes := services.NewChangeFeedService(service, storage.NewFileChangeLog(fh, "data", "changes.jsonl"))
+	moderation := services.NewModerationService(service, memory.NewSubmissionRepository(), 1)
+	h := NewHTTPHandlers(service, moderation, nil, audit, changes)
+
+	mux := http.NewServeMux()
+	Register(mux, Routes(h, config.Default(), metrics.NewRegistry()))
+	return RequestInfo(LimitBody(maxTestBody, mux))
+}
+
+func TestErrorEnvelope(t *testing.T) {
+	srv := limitServer(t)
+	board := func(extra string) string {
+		return `{"lettersMatrix": [["к", "о"], ["#", "т"]]` + extra + `}`
+	}
+	tests := []struct {
+		name, method, target, body string
+		status                     int
+		code, field                string
+	}{
+		// Bodies that don't decode
+		{"empty body", http.MethodPost, "/Words/Search", "", http.StatusBadRequest, codeInvalidRequest, ""},
+		{"not JSON", http.MethodPost, "/Words/Search", "lettersMatrix=кот", http.StatusBadRequest, codeInvalidRequest, ""},
+		{"cut short", http.MethodPost, "/Words/Search", `{"lettersMatrix": [["к"]`, http.StatusBadRequest, codeInvalidRequest, ""},
+		{"wrong type", http.MethodPost, "/Words/Search", `{"lettersMatrix": "кот"}`, http.StatusBadRequest, codeInvalidRequest, "lettersMatrix"},
+		{"body is an array", http.MethodPost, "/Words/Search", `[]`, http.StatusBadRequest, codeInvalidRequest, ""},
+		{"number that isn't", http.MethodPost, "/Words/Search", board(`, "maxWords": "ten"`), http.StatusBadRequest, codeInvalidRequest, ""},
+		{"too large", http.MethodPost, "/Words/Search", board(`, "language": "` + strings.Repeat("x", maxTestBody) + `"`), http.StatusRequestEntityTooLarge, codeTooLarge, ""},
+		{"malformed v1 body", http.MethodPost, "/v1/boards:solve", `{"lettersMatrix": [["к"]] "maxWords": 1}`, http.StatusBadRequest, codeInvalidRequest, ""},
+		{"malformed optional body", http.MethodPost, "/Words/Fsck", `{"fix": tru}`, http.StatusBadRequest, codeInvalidRequest, ""},
+
+		// Boards out of range
+		{"no rows", http.MethodPost, "/Words/Search", `{"lettersMatrix": []}`, http.StatusBadRequest, codeInvalidRequest, "lettersMatrix"},
+		{"too many rows", http.MethodPost, "/Words/Search", `{"lettersMatrix": [["к"], ["о"], ["т"], ["ы"]]}`, http.StatusBadRequest, codeInvalidRequest, "lettersMatrix"},
+		{"row too long", http.MethodPost, "/Words/Search", `{"lettersMatrix": [["к"], ["к", "о", "т", "ы"]]}`, http.StatusBadRequest, codeInvalidRequest, "lettersMatrix[1]"},
+		{"empty cell", http.MethodPost, "/Words/Search", `{"lettersMatrix": [["к", " "]]}`, http.StatusBadRequest, codeInvalidRequest, "lettersMatrix[0][1]"},
+		{"letter of another alphabet", http.MethodPost, "/Words/Search", `{"lettersMatrix": [["к"], ["#", "q"]]}`, http.StatusBadRequest, codeInvalidRequest, "lettersMatrix[1][1]"},
+		{"only masked cells", http.MethodPost, "/Words/Search", `{"lettersMatrix": [["#", "#"]]}`, http.StatusBadRequest, codeInvalidRequest, "lettersMatrix"},
+
+		// Options out of range
+		{"negative minLength", http.MethodPost, "/Words/Search", board(`, "minLength": -1`), http.StatusBadRequest, codeInvalidRequest, "minLength"},
+		{"negative maxLength", http.MethodPost, "/Words/Search", board(`, "maxLength": -1`), http.StatusBadRequest, codeInvalidRequest, "maxLength"},
+		{"minLength over maxLength", http.MethodPost, "/Words/Search", board(`, "minLength": 5, "maxLength": "3"`), http.StatusBadRequest, codeInvalidRequest, "minLength"},
+		{"negative maxWords", http.MethodPost, "/Words/Search", board(`, "maxWords": -1`), http.StatusBadRequest, codeInvalidRequest, "maxWords"},
+		{"unknown sortBy", http.MethodPost, "/Words/Search", board(`, "sortBy": "alphabet"`), http.StatusBadRequest, codeInvalidRequest, "sortBy"},
+		{"unknown maxRarity", http.MethodPost, "/v1/boards:solve", board(`, "maxRarity": "unheard"`), http.StatusBadRequest, codeInvalidRequest, "maxRarity"},
+		{"unknown language", http.MethodPost, "/Words/Search", board(`, "language": "xx"`), http.StatusBadRequest, codeUnknownLanguage, "language"},
+
+		// Query parameters
+		{"lookup without a word", http.MethodGet, "/Words/LookupWord?word=%20", "", http.StatusBadRequest, codeInvalidRequest, "word"},
+		{"since not a number", http.MethodGet, "/changes?since=latest", "", http.StatusBadRequest, codeInvalidRequest, "since"},
+		{"negative since", http.MethodGet, "/changes?since=-1", "", http.StatusBadRequest, codeInvalidRequest, "since"},
+		{"limit not a number", http.MethodGet, "/changes?limit=all", "", http.StatusBadRequest, codeInvalidRequest, "limit"},
+		{"audit since not a time", http.MethodGet, "/Words/Audit?since=yesterday", "", http.StatusBadRequest, codeInvalidRequest, "since"},
+		{"negative audit limit", http.MethodGet, "/Words/Audit?limit=-5", "", http.StatusBadRequest, codeInvalidRequest, "limit"},
+
+		// Routing
+		{"wrong method", http.MethodGet, "/Words/Search", "", http.StatusMethodNotAllowed, codeMethodNotAllowed, ""},
+		{"no such endpoint", http.MethodGet, "/Words/Nothing", "", http.StatusNotFound, codeNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			r.Header.Set(requestIDHeader, "req-42")
+			w := httptest.NewRecorder()
+			srv.ServeHTTP(w, r)
+
+			if w.Code != tt.status {
+				t.Fatalf("status %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Content-Type %q, want application/json", ct)
+			}
+
+			// The envelope is one "error" object with only the known keys
+			var envelope map[string]map[string]any
+			if err := json.Unmarshal(w.Body.Bytes(), &envelope); err != nil {
+				t.Fatalf("decoding %q: %v", w.Body, err)
+			}
+			body, ok := envelope["error"]
+			if !ok || len(envelope) != 1 {
+				t.Fatalf("reply %s, want only an error object", w.Body)
+			}
+			for key := range body {
+				if !slices.Contains([]string{"code", "message", "field", "requestId"}, key) {
+					t.Errorf("error has an unexpected %q", key)
+				}
+			}
+			if body["code"] != tt.code || body["requestId"] != "req-42" {
+				t.Errorf("error %v, want code %s for req-42", body, tt.code)
+			}
+			if message, _ := body["message"].(string); message == "" {
+				t.Error("error has no message")
+			}
+			if field, _ := body["field"].(string); field != tt.field {
+				t.Errorf("field %q, want %q", field, tt.field)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"service-matrix-go/internal/core/domain"
+)
+
+// Healthz endpoint: the process is up and serving
+func (h *HTTPHandlers) Healthz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		methodNotAllowed(w, r, http.MethodGet, http.MethodHead)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(domain.HealthResponse{Status: "ok"})
+}
+
+// Readyz endpoint: the dictionaries are loaded, the data directories are
+// writable and the server isn't shutting down
+func (h *HTTPHandlers) Readyz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		methodNotAllowed(w, r, http.MethodGet, http.MethodHead)
+		return
+	}
+
+	res := domain.HealthResponse{Status: "ok", Checks: h.service.Ready()}
+	for _, check := range res.Checks {
+		if !check.OK {
+			res.Status = "unavailable"
+		}
+	}
+	if h.draining.Load() {
+		res.Status = "draining"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if res.Status != "ok" {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(res)
+}
+
+// Drain makes /readyz fail so that load balancers stop sending requests
+func (h *HTTPHandlers) Drain() {
+	h.draining.Store(true)
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"service-matrix-go/internal/config"
+	"service-matrix-go/internal/core/domain"
+	"service-matrix-go/internal/metrics"
+)
+
+// health fetches a probe and decodes its reply, whatever the status
+func health(t *testing.T, handler http.Handler, target string) (int, domain.HealthResponse) {
+	t.Helper()
+	w := call(t, handler, http.MethodGet, target, "", nil)
+	var res domain.HealthResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("%s: decoding %q: %v", target, w.Body, err)
+	}
+	return w.Code, res
+}
+
+func TestReadiness(t *testing.T) {
+	service, _ := dictService(t, "кот\n")
+	h := NewHTTPHandlers(service, nil, nil, nil, nil)
+	mux := http.NewServeMux()
+	Register(mux, Routes(h, config.Default(), metrics.NewRegistry()))
+	srv := RequestInfo(mux)
+
+	// Up but not ready while the dictionaries load
+	if status, res := health(t, srv, "/healthz"); status != http.StatusOK || res.Status != "ok" {
+		t.Errorf("/healthz during startup: %d %+v, want 200 ok", status, res)
+	}
+	status, res := health(t, srv, "/readyz")
+	if status != http.StatusServiceUnavailable || res.Status != "unavailable" {
+		t.Errorf("/readyz during startup: %d %+v, want 503 unavailable", status, res)
+	}
+	for _, check := range res.Checks {
+		if check.Name == "dictionary ru" && (check.OK || check.Error == "") {
+			t.Errorf("ru dictionary check %+v before loading, want it failing", check)
+		}
+	}
+
+	if err := service.Preload(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	status, res = health(t, srv, "/readyz")
+	if status != http.StatusOK || res.Status != "ok" || len(res.Checks) == 0 {
+		t.Errorf("/readyz once loaded: %d %+v, want 200 ok with its checks", status, res)
+	}
+	if w := call(t, srv, http.MethodHead, "/readyz", "", nil); w.Code != http.StatusOK {
+		t.Errorf("HEAD /readyz: %d, want 200", w.Code)
+	}
+
+	// Draining fails readiness but not liveness
+	h.Drain()
+	if status, res := health(t, srv, "/readyz"); status != http.StatusServiceUnavailable || res.Status != "draining" {
+		t.Errorf("/readyz while draining: %d %+v, want 503 draining", status, res)
+	}
+	if status, _ := health(t, srv, "/healthz"); status != http.StatusOK {
+		t.Errorf("/healthz while draining: %d, want 200", status)
+	}
+
+	wantStatus(t, call(t, srv, http.MethodPost, "/readyz", "", nil), http.StatusMethodNotAllowed, codeMethodNotAllowed)
+}
+
+// TestGracefulShutdown shuts down like the server does: Drain, then
+// http.Server.Shutdown, with a request in flight
+func TestGracefulShutdown(t *testing.T) {
+	service, _ := dictService(t, "кот\n")
+	if err := service.Preload(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	h := NewHTTPHandlers(service, nil, nil, nil, nil)
+	started, release := make(chan struct{}), make(chan struct{})
+	routes := append(Routes(h, config.Default(), metrics.NewRegistry()), Route{
+		Pattern: "/slow",
+		Handler: func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-release
+			io.WriteString(w, "done")
+		},
+	})
+	mux := http.NewServeMux()
+	Register(mux, routes)
+	srv := httptest.NewServer(RequestInfo(mux))
+	defer srv.Close()
+
+	type reply struct {
+		status int
+		body   string
+		err    error
+	}
+	inFlight := make(chan reply, 1)
+	go func() {
+		res, err := http.Get(srv.URL + "/slow")
+		if err != nil {
+			inFlight <- reply{err: err}
+			return
+		}
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		inFlight <- reply{res.StatusCode, string(body), err}
+	}()
+	<-started
+
+	h.Drain()
+	res, err := http.Get(srv.URL + "/readyz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("/readyz after Drain: %d, want 503", res.StatusCode)
+	}
+
+	shutdown := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		shutdown <- srv.Config.Shutdown(ctx)
+	}()
+	select {
+	case err := <-shutdown:
+		t.Fatalf("Shutdown returned %v with a request in flight", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	if r := <-inFlight; r.err != nil || r.status != http.StatusOK || r.body != "done" {
+		t.Errorf("in-flight request finished with %d %q %v, want 200 done", r.status, r.body, r.err)
+	}
+	if err := <-shutdown; err != nil {
+		t.Errorf("Shutdown: %v", err)
+	}
+}
@@ -1,70 +1,187 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"net"
 	"net/http"
 	"service-matrix-go/internal/core/domain"
+	"service-matrix-go/internal/core/requestinfo"
 	"service-matrix-go/internal/core/services"
+	"strconv"
+	"sync/atomic"
+	"time"
 )
 
 type HTTPHandlers struct {
-	service *services.WordService
+	service    *services.WordService
+	moderation *services.ModerationService
+	snapshots  *services.SnapshotService
+	audit      *services.AuditService
+	changes    *services.ChangeFeedService
+
+	// draining is set once the server starts shutting down
+	draining atomic.Bool
 }
 
-func NewHTTPHandlers(s *services.WordService) *HTTPHandlers {
-	return &HTTPHandlers{service: s}
+func NewHTTPHandlers(s *services.WordService, m *services.ModerationService, snaps *services.SnapshotService, audit *services.AuditService, changes *services.ChangeFeedService) *HTTPHandlers {
+	return &HTTPHandlers{service: s, moderation: m, snapshots: snaps, audit: audit, changes: changes}
 }
 
 // Search endpoint
 func (h *HTTPHandlers) Search(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		methodNotAllowed(w, r, http.MethodPost)
 		return
 	}
 
 	var req domain.SearchRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if !decodeJSON(w, r, &req) {
 		return
 	}
 
-	res, err := h.service.Search(req)
+	res, err := h.service.Search(r.Context(), req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, r, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(res)
+	if req.IncludeDetails {
+		json.NewEncoder(w).Encode(domain.SearchResponse{Words: res})
+		return
+	}
+
+	// Legacy shape: word -> letter index -> letter -> "row col"
+	legacy := make(map[string]map[int]map[string]string, len(res))
+	for _, found := range res {
+		legacy[found.Word] = found.Path
+	}
+	json.NewEncoder(w).Encode(legacy)
 }
 
 // Update endpoint
 func (h *HTTPHandlers) Update(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		methodNotAllowed(w, r, http.MethodPost)
 		return
 	}
 
 	var req domain.UpdateWordsRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	// Votes are counted per caller, so the body can't name the submitter
+	req.Submitter = caller(r)
+
+	// Words go to the moderation queue rather than straight into the list
+	res, err := h.moderation.Submit(r.Context(), req)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// ListSubmissions endpoint
+func (h *HTTPHandlers) ListSubmissions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+
+	res, err := h.moderation.List(r.Context(), r.URL.Query().Get("status"), r.URL.Query().Get("language"))
+	if err != nil {
+		writeServiceError(w, r, err)
 		return
 	}
 
-	count, err := h.service.UpdateWords(req)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// ApproveSubmission endpoint
+func (h *HTTPHandlers) ApproveSubmission(w http.ResponseWriter, r *http.Request) {
+	h.decideSubmission(w, r, h.moderation.Approve)
+}
+
+// RejectSubmission endpoint
+func (h *HTTPHandlers) RejectSubmission(w http.ResponseWriter, r *http.Request) {
+	h.decideSubmission(w, r, h.moderation.Reject)
+}
+
+func (h *HTTPHandlers) decideSubmission(w http.ResponseWriter, r *http.Request, decide func(context.Context, domain.SubmissionDecisionRequest, string) (domain.Submission, error)) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, r, http.MethodPost)
+		return
+	}
+
+	var req domain.SubmissionDecisionRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	res, err := decide(r.Context(), req, caller(r))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, r, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(count)
+	json.NewEncoder(w).Encode(res)
+}
+
+// RemoveWords endpoint
+func (h *HTTPHandlers) RemoveWords(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, r, http.MethodPost)
+		return
+	}
+
+	var req domain.RemoveWordsRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	res, err := h.service.RemoveWords(r.Context(), req)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// RemoveMerged endpoint
+func (h *HTTPHandlers) RemoveMerged(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, r, http.MethodPost)
+		return
+	}
+
+	var req domain.RemoveMergedRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	res, err := h.service.RemoveMerged(r.Context(), req)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
 }
 
 // GetList endpoint
 func (h *HTTPHandlers) GetList(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		methodNotAllowed(w, r, http.MethodGet)
 		return
 	}
 
@@ -73,9 +190,27 @@ func (h *HTTPHandlers) GetList(w http.ResponseWriter, r *http.Request) {
 		include = false
 	}
 
-	res, err := h.service.GetList(include)
+	res, err := h.service.GetList(r.Context(), r.URL.Query().Get("language"), include)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// PreviewMerge endpoint. It is a POST because staging replaces the last
+// preview's candidates.
+func (h *HTTPHandlers) PreviewMerge(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, r, http.MethodPost)
+		return
+	}
+
+	res, err := h.service.PreviewMerge(r.Context(), r.URL.Query().Get("language"))
+	if err != nil {
+		writeServiceError(w, r, err)
 		return
 	}
 
@@ -86,13 +221,22 @@ func (h *HTTPHandlers) GetList(w http.ResponseWriter, r *http.Request) {
 // MergeWords endpoint
 func (h *HTTPHandlers) MergeWords(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		methodNotAllowed(w, r, http.MethodPost)
+		return
+	}
+
+	// The body is optional; without it every previewed candidate is merged
+	var req domain.MergeCommitRequest
+	if !decodeOptionalJSON(w, r, &req) {
 		return
 	}
+	if req.Language == "" {
+		req.Language = r.URL.Query().Get("language")
+	}
 
-	res, err := h.service.MergeWords()
+	res, err := h.service.MergeWords(r.Context(), req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, r, err)
 		return
 	}
 
@@ -103,13 +247,13 @@ func (h *HTTPHandlers) MergeWords(w http.ResponseWriter, r *http.Request) {
 // CleanMerge endpoint
 func (h *HTTPHandlers) CleanMerge(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		methodNotAllowed(w, r, http.MethodGet)
 		return
 	}
 
-	res, err := h.service.CleanMerge()
+	res, err := h.service.CleanMerge(r.Context(), r.URL.Query().Get("language"))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, r, err)
 		return
 	}
 
@@ -120,22 +264,238 @@ func (h *HTTPHandlers) CleanMerge(w http.ResponseWriter, r *http.Request) {
 // LookupWord endpoint
 func (h *HTTPHandlers) LookupWord(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		methodNotAllowed(w, r, http.MethodGet)
 		return
 	}
 
 	word := r.URL.Query().Get("word")
-    exactMatch := false
-    if r.URL.Query().Get("exactMatch") == "true" {
-        exactMatch = true
-    }
+	exactMatch := false
+	if r.URL.Query().Get("exactMatch") == "true" {
+		exactMatch = true
+	}
 
-	res, err := h.service.LookupWord(word, exactMatch)
+	details := r.URL.Query().Get("details") == "true"
+
+	res, err := h.service.LookupWord(r.Context(), r.URL.Query().Get("language"), word, exactMatch, details)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, r, err)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(res)
 }
+
+// Snapshots endpoint: GET lists the snapshots of a language, POST takes one
+func (h *HTTPHandlers) Snapshots(w http.ResponseWriter, r *http.Request) {
+	var res interface{}
+	var err error
+	switch r.Method {
+	case http.MethodGet:
+		res, err = h.snapshots.List(r.Context(), r.URL.Query().Get("language"))
+	case http.MethodPost:
+		var req domain.CreateSnapshotRequest
+		if !decodeOptionalJSON(w, r, &req) {
+			return
+		}
+		if req.Language == "" {
+			req.Language = r.URL.Query().Get("language")
+		}
+		res, err = h.snapshots.Create(r.Context(), req)
+	default:
+		methodNotAllowed(w, r, http.MethodGet, http.MethodPost)
+		return
+	}
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// DiffSnapshots endpoint; without "to" the snapshot is compared with the current state
+func (h *HTTPHandlers) DiffSnapshots(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+
+	from, err := strconv.Atoi(r.URL.Query().Get("from"))
+	if err != nil {
+		writeInvalidParam(w, r, "from", "from must be a snapshot id")
+		return
+	}
+	to := 0
+	if v := r.URL.Query().Get("to"); v != "" {
+		if to, err = strconv.Atoi(v); err != nil {
+			writeInvalidParam(w, r, "to", "to must be a snapshot id")
+			return
+		}
+	}
+
+	res, err := h.snapshots.Diff(r.Context(), from, to)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// RollbackSnapshot endpoint
+func (h *HTTPHandlers) RollbackSnapshot(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, r, http.MethodPost)
+		return
+	}
+
+	var req domain.RollbackRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	res, err := h.snapshots.Rollback(r.Context(), req)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// AuditLog endpoint; since and until are RFC 3339 times
+func (h *HTTPHandlers) AuditLog(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+
+	query := r.URL.Query()
+	q := domain.AuditQuery{
+		Language:  query.Get("language"),
+		Operation: query.Get("operation"),
+		List:      query.Get("list"),
+		Word:      query.Get("word"),
+		Client:    query.Get("client"),
+		Key:       query.Get("key"),
+		RequestID: query.Get("requestId"),
+	}
+	var err error
+	if v := query.Get("since"); v != "" {
+		if q.Since, err = time.Parse(time.RFC3339, v); err != nil {
+			writeInvalidParam(w, r, "since", "since must be an RFC 3339 time")
+			return
+		}
+	}
+	if v := query.Get("until"); v != "" {
+		if q.Until, err = time.Parse(time.RFC3339, v); err != nil {
+			writeInvalidParam(w, r, "until", "until must be an RFC 3339 time")
+			return
+		}
+	}
+	if v := query.Get("limit"); v != "" {
+		if q.Limit, err = strconv.Atoi(v); err != nil || q.Limit < 0 {
+			writeInvalidParam(w, r, "limit", "limit must be a non-negative number")
+			return
+		}
+	}
+
+	res, err := h.audit.Query(r.Context(), q)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// Changes endpoint: the feed of list mutations after version "since"
+func (h *HTTPHandlers) Changes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		methodNotAllowed(w, r, http.MethodGet)
+		return
+	}
+
+	var since int64
+	var limit int
+	var err error
+	if v := r.URL.Query().Get("since"); v != "" {
+		if since, err = strconv.ParseInt(v, 10, 64); err != nil {
+			writeInvalidParam(w, r, "since", "since must be a version number")
+			return
+		}
+	}
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil {
+			writeInvalidParam(w, r, "limit", "limit must be a number")
+			return
+		}
+	}
+
+	res, err := h.changes.Since(r.Context(), since, limit)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// Fsck endpoint: GET reports problems in a language's lists, POST with
+// {"fix": true} also repairs them
+func (h *HTTPHandlers) Fsck(w http.ResponseWriter, r *http.Request) {
+	req := domain.FsckRequest{Limit: 1000}
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		if !decodeOptionalJSON(w, r, &req) {
+			return
+		}
+	default:
+		methodNotAllowed(w, r, http.MethodGet, http.MethodPost)
+		return
+	}
+	if req.Language == "" {
+		req.Language = r.URL.Query().Get("language")
+	}
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			writeInvalidParam(w, r, "limit", "limit must be a number")
+			return
+		}
+		req.Limit = limit
+	}
+
+	res, err := h.service.Check(r.Context(), req)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// caller names who made r: its API key, or else its address
+func caller(r *http.Request) string {
+	if key := requestinfo.FromContext(r.Context()).Key; key != "" {
+		return key
+	}
+	return clientAddress(r)
+}
+
+// clientAddress identifies the caller by the host part of its address
+func clientAddress(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"service-matrix-go/internal/core/domain"
+	"service-matrix-go/internal/core/language"
+	"service-matrix-go/internal/core/ports"
+	"service-matrix-go/internal/core/requestinfo"
+	"service-matrix-go/internal/core/services"
+	"service-matrix-go/internal/infrastructure/memory"
+)
+
+// testHandlers serves from in-memory repositories; a word needs two votes
+// from distinct callers to be approved
+func testHandlers(t *testing.T) (*HTTPHandlers, *memory.WordRepository) {
+	t.Helper()
+	words := memory.NewWordRepository()
+	service := services.NewWordService(nil, words, language.NewRegistry())
+	moderation := services.NewModerationService(service, memory.NewSubmissionRepository(), 2)
+	return NewHTTPHandlers(service, moderation, nil, nil, nil), words
+}
+
+// serve runs handler on a request made with the named API key, or
+// anonymously from 192.0.2.1 when key is empty
+func serve(handler http.HandlerFunc, method, target, key, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	r.RemoteAddr = "192.0.2.1:40000"
+	if key != "" {
+		r = r.WithContext(requestinfo.WithInfo(r.Context(), requestinfo.Info{Key: key}))
+	}
+	w := httptest.NewRecorder()
+	handler(w, r)
+	return w
+}
+
+func submit(t *testing.T, h *HTTPHandlers, key, body string) domain.Submission {
+	t.Helper()
+	w := serve(h.Update, http.MethodPost, "/Words/Update", key, body)
+	if w.Code != http.StatusOK {
+		t.Fatalf("POST /Words/Update as %q: status %d: %s", key, w.Code, w.Body)
+	}
+	var res domain.SubmitWordsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if len(res.Submissions) != 1 {
+		t.Fatalf("got %d submissions, want 1: %s", len(res.Submissions), w.Body)
+	}
+	return res.Submissions[0]
+}
+
+func TestUpdateCountsOneVotePerKey(t *testing.T) {
+	h, _ := testHandlers(t)
+
+	// The body can't pose as someone else to add votes
+	for _, submitter := range []string{"", "alice", "bob", "carol"} {
+		body := `{"words": ["кот"], "include": true, "submitter": "` + submitter + `"}`
+		sub := submit(t, h, "bot", body)
+		if sub.Votes != 1 || sub.Status != domain.SubmissionPending {
+			t.Fatalf("after submitting as %q: %d votes, status %s; want 1 vote, pending", submitter, sub.Votes, sub.Status)
+		}
+		if sub.Submitter != "bot" || len(sub.Voters) != 1 || sub.Voters[0] != "bot" {
+			t.Fatalf("after submitting as %q: submitter %q, voters %q; want bot", submitter, sub.Submitter, sub.Voters)
+		}
+	}
+}
+
+func TestUpdateApprovesOnVotesFromDistinctCallers(t *testing.T) {
+	h, words := testHandlers(t)
+
+	submit(t, h, "bot", `{"words": ["кот"], "include": true}`)
+	sub := submit(t, h, "", `{"words": ["кот"], "include": true, "submitter": "bot"}`)
+	if sub.Votes != 2 || sub.Status != domain.SubmissionApproved || !sub.AutoApproved {
+		t.Fatalf("got %d votes, status %s, auto %v; want 2 votes, auto-approved", sub.Votes, sub.Status, sub.AutoApproved)
+	}
+	if want := []string{"bot", "192.0.2.1"}; strings.Join(sub.Voters, ",") != strings.Join(want, ",") {
+		t.Errorf("voters %q, want %q", sub.Voters, want)
+	}
+
+	list, err := words.List(context.Background(), ports.ListRef{Language: "ru", Name: ports.ListInclude})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0] != "кот" {
+		t.Errorf("include list is %q, want [кот]", list)
+	}
+}
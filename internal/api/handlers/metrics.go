@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"service-matrix-go/internal/metrics"
+)
+
+// HTTPMetrics counts and times requests per route and status
+type HTTPMetrics struct {
+	requests *metrics.Counter
+	duration *metrics.Histogram
+}
+
+// NewHTTPMetrics registers the request metrics in reg
+func NewHTTPMetrics(reg *metrics.Registry) *HTTPMetrics {
+	return &HTTPMetrics{
+		requests: reg.NewCounter("matrix_http_requests_total",
+			"HTTP requests by route, method and status.",
+			"route", "method", "status"),
+		duration: reg.NewHistogram("matrix_http_request_duration_seconds",
+			"HTTP request latency by route and status.",
+			metrics.DefaultBuckets, "route", "status"),
+	}
+}
+
+// Instrument records every request to next under route
+func (m *HTTPMetrics) Instrument(route string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		status := strconv.Itoa(rec.status)
+		m.requests.Inc(route, r.Method, status)
+		m.duration.Observe(time.Since(started).Seconds(), route, status)
+	})
+}
+
+// Wrap records every request to the route
+func (m *HTTPMetrics) Wrap(route Route, next http.Handler) http.Handler {
+	return m.Instrument(route.label(), next)
+}
+
+// statusRecorder remembers the status code a handler wrote
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// Metrics serves reg for Prometheus to scrape
+func Metrics(reg *metrics.Registry) http.HandlerFunc {
+	serve := reg.Handler()
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			methodNotAllowed(w, r, http.MethodGet)
+			return
+		}
+
+		serve(w, r)
+	}
+}
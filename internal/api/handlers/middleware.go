@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"service-matrix-go/internal/core/requestinfo"
+)
+
+// requestIDHeader carries the request ID in and out
+const requestIDHeader = "X-Request-ID"
+
+// RequestInfo tags each request with its client and request ID, taking the
+// ID from X-Request-ID when the caller sends one, and echoes the ID back
+func RequestInfo(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if id == "" || len(id) > 128 {
+			id = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, id)
+
+		ctx := requestinfo.WithInfo(r.Context(), requestinfo.Info{RequestID: id, Client: clientAddress(r)})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// LimitBody caps request bodies at maxBytes; decoding a larger body replies 413
+func LimitBody(maxBytes int64, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// probeRoutes are polled constantly, so their successes are only logged at debug
+var probeRoutes = map[string]bool{"/healthz": true, "/readyz": true}
+
+// LogRequests logs each request to the route once it has been served
+func LogRequests(route Route, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		level := slog.LevelInfo
+		switch {
+		case rec.status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case probeRoutes[route.Pattern] && rec.status < http.StatusBadRequest:
+			level = slog.LevelDebug
+		}
+		slog.Log(r.Context(), level, "request",
+			"method", r.Method,
+			"route", route.label(),
+			"path", r.URL.Path,
+			"status", rec.status,
+			"duration_ms", time.Since(started).Milliseconds(),
+		)
+	})
+}
+
+// Recover turns a panic in the route's handler into a 500 error reply and
+// logs it with the stack, so one bad request can't take the connection down
+// without an answer. It is meant to be the innermost middleware, so that the
+// request is still logged and counted.
+func Recover(route Route, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+			if p == http.ErrAbortHandler {
+				panic(p)
+			}
+			slog.ErrorContext(r.Context(), "panic serving request",
+				"method", r.Method,
+				"route", route.label(),
+				"path", r.URL.Path,
+				"panic", p,
+				"stack", string(debug.Stack()),
+			)
+			// Too late for an error reply once the handler has started its own
+			if !rec.wroteHeader {
+				writeError(rec, r, http.StatusInternalServerError, codeInternal, "", "internal error")
+			}
+		}()
+		next.ServeHTTP(rec, r)
+	})
+}
@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"service-matrix-go/internal/core/domain"
+	"service-matrix-go/internal/core/requestinfo"
+	"service-matrix-go/internal/logging"
+)
+
+// logBuffer collects the lines written by a JSON logger
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// records decodes every record logged so far
+func (b *logBuffer) records(t *testing.T) []map[string]any {
+	t.Helper()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("decoding log line %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// captureLogs makes the default logger the server's JSON logger, at debug,
+// writing to the returned buffer until the test ends
+func captureLogs(t *testing.T) *logBuffer {
+	t.Helper()
+	b := &logBuffer{}
+	logger, err := logging.New(b, "debug", logging.FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := slog.Default()
+	slog.SetDefault(logger)
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return b
+}
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name, header string
+		// kept is whether the client's ID is used
+		kept bool
+	}{
+		{"sent by the client", "client-id-1", true},
+		{"missing", "", false},
+		{"too long", strings.Repeat("x", 129), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := captureLogs(t)
+			var seen string
+			handler := RequestInfo(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seen = requestinfo.FromContext(r.Context()).RequestID
+				slog.InfoContext(r.Context(), "handling")
+			}))
+			r := httptest.NewRequest(http.MethodGet, "/Words/List", nil)
+			if tt.header != "" {
+				r.Header.Set(requestIDHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			id := w.Header().Get(requestIDHeader)
+			if tt.kept && id != tt.header {
+				t.Errorf("replied with ID %q, want the client's %q", id, tt.header)
+			}
+			if !tt.kept && (id == "" || id == tt.header) {
+				t.Errorf("replied with ID %q, want a generated one", id)
+			}
+			if seen != id {
+				t.Errorf("handler saw ID %q, reply has %q", seen, id)
+			}
+			records := logs.records(t)
+			if len(records) != 1 || records[0]["request_id"] != id {
+				t.Errorf("logged %v, want one record with request_id %q", records, id)
+			}
+		})
+	}
+
+	// Each request gets its own ID
+	first, second := httptest.NewRecorder(), httptest.NewRecorder()
+	handler := RequestInfo(http.HandlerFunc(noContent))
+	handler.ServeHTTP(first, httptest.NewRequest(http.MethodGet, "/", nil))
+	handler.ServeHTTP(second, httptest.NewRequest(http.MethodGet, "/", nil))
+	if first.Header().Get(requestIDHeader) == second.Header().Get(requestIDHeader) {
+		t.Errorf("two requests both got ID %q", first.Header().Get(requestIDHeader))
+	}
+}
+
+func TestLogRequests(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		status  int
+		level   string
+	}{
+		{"success", "/Words/Search", http.StatusOK, "INFO"},
+		{"client error", "/Words/Search", http.StatusBadRequest, "INFO"},
+		{"server error", "/Words/Search", http.StatusInternalServerError, "ERROR"},
+		{"probe", "/healthz", http.StatusOK, "DEBUG"},
+		{"failing probe", "/readyz", http.StatusServiceUnavailable, "ERROR"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := captureLogs(t)
+			handler := RequestInfo(LogRequests(Route{Pattern: tt.pattern}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(5 * time.Millisecond)
+				w.WriteHeader(tt.status)
+			})))
+			r := httptest.NewRequest(http.MethodPost, tt.pattern+"?language=ru", nil)
+			r.Header.Set(requestIDHeader, "req-1")
+			handler.ServeHTTP(httptest.NewRecorder(), r)
+
+			records := logs.records(t)
+			if len(records) != 1 {
+				t.Fatalf("logged %v, want one record", records)
+			}
+			record := records[0]
+			want := map[string]any{
+				"level":      tt.level,
+				"msg":        "request",
+				"method":     http.MethodPost,
+				"route":      tt.pattern,
+				"path":       tt.pattern,
+				"status":     float64(tt.status),
+				"request_id": "req-1",
+			}
+			for key, value := range want {
+				if record[key] != value {
+					t.Errorf("%s = %v, want %v", key, record[key], value)
+				}
+			}
+			if ms, ok := record["duration_ms"].(float64); !ok || ms < 5 {
+				t.Errorf("duration_ms = %v, want at least the 5ms the handler took", record["duration_ms"])
+			}
+		})
+	}
+}
+
+func TestRecover(t *testing.T) {
+	route := Route{Pattern: "/Words/Search"}
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		status  int
+		// envelope is whether the reply is the error envelope
+		envelope bool
+	}{
+		{"panics", func(w http.ResponseWriter, r *http.Request) { panic("boom") }, http.StatusInternalServerError, true},
+		{"panics with an error", func(w http.ResponseWriter, r *http.Request) {
+			var m map[string]int
+			m["x"]++
+		}, http.StatusInternalServerError, true},
+		// Too late to change the reply, so what was sent stands
+		{"panics after replying", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+			panic("boom")
+		}, http.StatusAccepted, false},
+		{"doesn't panic", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) }, http.StatusNoContent, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/Words/Search", nil)
+			r = r.WithContext(requestinfo.WithInfo(r.Context(), requestinfo.Info{RequestID: "req-1"}))
+			w := httptest.NewRecorder()
+			Recover(route, tt.handler).ServeHTTP(w, r)
+
+			if w.Code != tt.status {
+				t.Errorf("status %d, want %d", w.Code, tt.status)
+			}
+			if !tt.envelope {
+				return
+			}
+			var res domain.ErrorResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+				t.Fatalf("decoding %q: %v", w.Body, err)
+			}
+			if res.Error.Code != codeInternal || res.Error.RequestID != "req-1" || res.Error.Message != "internal error" {
+				t.Errorf("error %+v, want internal error for req-1", res.Error)
+			}
+		})
+	}
+}
+
+func TestRecoverLetsAbortsThrough(t *testing.T) {
+	handler := Recover(Route{Pattern: "/Words/Search"}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	defer func() {
+		if p := recover(); p != http.ErrAbortHandler {
+			t.Errorf("recovered %v, want http.ErrAbortHandler", p)
+		}
+	}()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/Words/Search", nil))
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// openAPISpec documents every route; routes_test.go keeps the two in sync
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+//go:embed docs.html
+var docsPage []byte
+
+// OpenAPI endpoint: the OpenAPI 3 document of the API
+func OpenAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		methodNotAllowed(w, r, http.MethodGet, http.MethodHead)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(openAPISpec)
+}
+
+// Docs endpoint: a page that renders the OpenAPI document
+func Docs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		methodNotAllowed(w, r, http.MethodGet, http.MethodHead)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(docsPage)
+}
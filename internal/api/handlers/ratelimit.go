@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"service-matrix-go/internal/core/requestinfo"
+	"service-matrix-go/internal/ratelimit"
+)
+
+// Budget names the rate limit a route's writes count against
+type Budget string
+
+const (
+	// BudgetNone leaves a route unlimited
+	BudgetNone Budget = ""
+	// BudgetSearch is for board searches, which also share the concurrency cap
+	BudgetSearch Budget = "search"
+	// BudgetMutation is for requests that change the word lists
+	BudgetMutation Budget = "mutation"
+)
+
+// RateLimiter keeps per-client budgets and caps how many searches run at once
+type RateLimiter struct {
+	limiters map[Budget]*ratelimit.Limiter
+	searches chan struct{}
+}
+
+// NewRateLimiter limits each client to the search and mutation rates and
+// runs at most maxSearches searches at once (0 for no cap)
+func NewRateLimiter(search, mutation ratelimit.Rate, maxSearches int) *RateLimiter {
+	l := &RateLimiter{limiters: map[Budget]*ratelimit.Limiter{
+		BudgetSearch:   ratelimit.NewLimiter(search),
+		BudgetMutation: ratelimit.NewLimiter(mutation),
+	}}
+	if maxSearches > 0 {
+		l.searches = make(chan struct{}, maxSearches)
+	}
+	return l
+}
+
+// Limit charges writes to next against budget; reads are never limited.
+// Requests over budget get 429 with Retry-After.
+func (l *RateLimiter) Limit(budget Budget, next http.HandlerFunc) http.HandlerFunc {
+	limiter := l.limiters[budget]
+	if limiter == nil {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			next(w, r)
+			return
+		}
+
+		info := requestinfo.FromContext(r.Context())
+		client := "address " + info.Client
+		if info.Key != "" {
+			client = "key " + info.Key
+		}
+		if ok, wait := limiter.Allow(client); !ok {
+			tooManyRequests(w, r, wait, "rate limit exceeded for "+string(budget)+" requests")
+			return
+		}
+
+		if budget == BudgetSearch && l.searches != nil {
+			select {
+			case l.searches <- struct{}{}:
+				defer func() { <-l.searches }()
+			default:
+				tooManyRequests(w, r, time.Second, "too many searches in progress")
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
+// Wrap charges the route's writes against its budget
+func (l *RateLimiter) Wrap(route Route, next http.Handler) http.Handler {
+	return l.Limit(route.Budget, next.ServeHTTP)
+}
+
+func tooManyRequests(w http.ResponseWriter, r *http.Request, wait time.Duration, message string) {
+	seconds := int(math.Ceil(wait.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	writeError(w, r, http.StatusTooManyRequests, codeRateLimited, "", message)
+}
@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"service-matrix-go/internal/config"
+	"service-matrix-go/internal/metrics"
+	"service-matrix-go/internal/ratelimit"
+)
+
+// limitedServer charges POSTs to budget against rate on a clock the test
+// moves by hand
+func limitedServer(budget Budget, rate ratelimit.Rate, maxSearches int, next http.HandlerFunc) (http.Handler, *time.Time) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := NewRateLimiter(ratelimit.Rate{}, ratelimit.Rate{}, maxSearches)
+	l.limiters[budget] = ratelimit.NewLimiterWithClock(rate, func() time.Time { return now })
+	return RequestInfo(l.Limit(budget, next)), &now
+}
+
+// from sends method to handler from the client at address
+func from(handler http.Handler, method, address string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, "/Words/Search", nil)
+	r.RemoteAddr = address + ":1234"
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+func noContent(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) }
+
+func TestRateLimitRefusesWithRetryAfter(t *testing.T) {
+	handler, now := limitedServer(BudgetMutation, ratelimit.Rate{PerSecond: 0.4, Burst: 2}, 0, noContent)
+	for range 2 {
+		wantStatus(t, from(handler, http.MethodPost, "192.0.2.1"), http.StatusNoContent, "")
+	}
+
+	w := from(handler, http.MethodPost, "192.0.2.1")
+	wantStatus(t, w, http.StatusTooManyRequests, codeRateLimited)
+	if got := w.Header().Get("Retry-After"); got != "3" {
+		t.Errorf("Retry-After %q, want the 2.5s wait rounded up to 3", got)
+	}
+
+	// Another client has its own budget
+	wantStatus(t, from(handler, http.MethodPost, "192.0.2.2"), http.StatusNoContent, "")
+
+	// Most of the way there, the wait left is still at least a second
+	*now = now.Add(2400 * time.Millisecond)
+	w = from(handler, http.MethodPost, "192.0.2.1")
+	wantStatus(t, w, http.StatusTooManyRequests, codeRateLimited)
+	if got := w.Header().Get("Retry-After"); got != "1" {
+		t.Errorf("Retry-After %q with 100ms to go, want 1", got)
+	}
+
+	*now = now.Add(100 * time.Millisecond)
+	wantStatus(t, from(handler, http.MethodPost, "192.0.2.1"), http.StatusNoContent, "")
+}
+
+func TestRateLimitExemptsReads(t *testing.T) {
+	handler, _ := limitedServer(BudgetSearch, ratelimit.Rate{PerSecond: 1, Burst: 1}, 0, noContent)
+	wantStatus(t, from(handler, http.MethodPost, "192.0.2.1"), http.StatusNoContent, "")
+	wantStatus(t, from(handler, http.MethodPost, "192.0.2.1"), http.StatusTooManyRequests, codeRateLimited)
+
+	// With the budget spent, reads still go through and don't touch it
+	for _, method := range []string{http.MethodGet, http.MethodHead, http.MethodGet} {
+		if w := from(handler, method, "192.0.2.1"); w.Code != http.StatusNoContent {
+			t.Errorf("%s over budget: status %d, want it let through", method, w.Code)
+		}
+	}
+	wantStatus(t, from(handler, http.MethodPost, "192.0.2.1"), http.StatusTooManyRequests, codeRateLimited)
+}
+
+func TestRateLimitCapsConcurrentSearches(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	handler, _ := limitedServer(BudgetSearch, ratelimit.Rate{}, 1, func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	done := make(chan int)
+	go func() { done <- from(handler, http.MethodPost, "192.0.2.1").Code }()
+	<-started
+
+	w := from(handler, http.MethodPost, "192.0.2.2")
+	wantStatus(t, w, http.StatusTooManyRequests, codeRateLimited)
+	if got := w.Header().Get("Retry-After"); got != "1" {
+		t.Errorf("Retry-After %q, want 1", got)
+	}
+
+	close(release)
+	if code := <-done; code != http.StatusNoContent {
+		t.Errorf("running search finished with %d", code)
+	}
+}
+
+func TestMergePreviewsAreCharged(t *testing.T) {
+	service, _ := dictService(t, "кот\n")
+	h := NewHTTPHandlers(service, nil, nil, nil, nil)
+	limiter := NewRateLimiter(ratelimit.Rate{}, ratelimit.Rate{PerSecond: 0.001, Burst: 1}, 0)
+	mux := http.NewServeMux()
+	Register(mux, Routes(h, config.Default(), metrics.NewRegistry()), limiter.Wrap)
+	srv := RequestInfo(mux)
+
+	// Staging candidates is a mutation, so a GET can't get round the budget
+	for _, target := range []string{"/Words/Merge/Preview", "/v1/dictionary:merge-preview"} {
+		wantStatus(t, call(t, srv, http.MethodGet, target, "", nil), http.StatusMethodNotAllowed, codeMethodNotAllowed)
+	}
+	wantStatus(t, call(t, srv, http.MethodPost, "/Words/Merge/Preview", "", nil), http.StatusOK, "")
+	wantStatus(t, call(t, srv, http.MethodPost, "/v1/dictionary:merge-preview", "", nil), http.StatusTooManyRequests, codeRateLimited)
+}
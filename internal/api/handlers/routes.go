@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"service-matrix-go/internal/auth"
+	"service-matrix-go/internal/config"
+	"service-matrix-go/internal/metrics"
+)
+
+// Route is one endpoint and the roles it needs: Read for GET and HEAD
+// requests, Write for every other method. Writes count against Budget.
+type Route struct {
+	Pattern string
+	Handler http.HandlerFunc
+	Read    auth.Role
+	Write   auth.Role
+	Budget  Budget
+}
+
+// Routes lists every endpoint with the roles and rate limit budget it needs.
+// The /v1 API is routed by method and path. The legacy paths follow the
+// original C# controller, [Route("[controller]")] with [HttpPost("Search")]
+// becoming /Words/Search, and stay as aliases.
+func Routes(h *HTTPHandlers, cfg config.Config, reg *metrics.Registry) []Route {
+	const (
+		anyone      = auth.RoleNone
+		reader      = auth.RoleReader
+		contributor = auth.RoleContributor
+		admin       = auth.RoleAdmin
+	)
+	return []Route{
+		{"GET /v1/words/{word}", h.GetWord, reader, reader, BudgetNone},
+		{"POST /v1/boards:solve", h.SolveBoard, reader, reader, BudgetSearch},
+		{"GET /v1/lists/{list}/{word}", h.GetListWord, reader, reader, BudgetNone},
+		{"POST /v1/lists/{list}/{word}", h.AddListWord, reader, contributor, BudgetMutation},
+		{"DELETE /v1/lists/{list}/{word}", h.DeleteListWord, reader, admin, BudgetMutation},
+		{"POST /v1/dictionary:merge-preview", h.PreviewDictionaryMerge, admin, admin, BudgetMutation},
+		{"POST /v1/dictionary:merge", h.MergeWords, admin, admin, BudgetMutation},
+
+		{"/Words/Search", h.Search, reader, reader, BudgetSearch},
+		{"/Words/LookupWord", h.LookupWord, reader, reader, BudgetNone},
+		{"/Words/List", h.GetList, reader, reader, BudgetNone},
+		{"/Words/Update", h.Update, contributor, contributor, BudgetMutation},
+		{"/Words/Submissions", h.ListSubmissions, contributor, contributor, BudgetNone},
+		{"/Words/Submissions/Approve", h.ApproveSubmission, admin, admin, BudgetMutation},
+		{"/Words/Submissions/Reject", h.RejectSubmission, admin, admin, BudgetMutation},
+		{"/Words/Remove", h.RemoveWords, admin, admin, BudgetMutation},
+		{"/Words/RemoveMerged", h.RemoveMerged, admin, admin, BudgetMutation},
+		{"/Words/Merge/Preview", h.PreviewMerge, admin, admin, BudgetMutation},
+		{"/Words/Merge", h.MergeWords, admin, admin, BudgetMutation},
+		{"/Words/CleanMerge", h.CleanMerge, admin, admin, BudgetMutation},
+		{"/Words/Snapshots", h.Snapshots, reader, admin, BudgetMutation},
+		{"/Words/Snapshots/Diff", h.DiffSnapshots, reader, reader, BudgetNone},
+		{"/Words/Snapshots/Rollback", h.RollbackSnapshot, admin, admin, BudgetMutation},
+		{"/Words/Audit", h.AuditLog, admin, admin, BudgetNone},
+		{"/Words/Fsck", h.Fsck, admin, admin, BudgetMutation},
+		{"/changes", h.Changes, reader, reader, BudgetNone},
+		{"/debug/config", DebugConfig(cfg), admin, admin, BudgetNone},
+		{"/metrics", Metrics(reg), reader, reader, BudgetNone},
+		{"/healthz", h.Healthz, anyone, anyone, BudgetNone},
+		{"/readyz", h.Readyz, anyone, anyone, BudgetNone},
+		{"/openapi.json", OpenAPI, anyone, anyone, BudgetNone},
+		{"/docs", Docs, anyone, anyone, BudgetNone},
+	}
+}
+
+// unmatchedRoute labels requests no route matched in metrics and logs
+const unmatchedRoute = "unmatched"
+
+// label names the route in metrics and logs: its path, as the method is
+// recorded separately
+func (r Route) label() string {
+	if r.Pattern == "/" {
+		return unmatchedRoute
+	}
+	return r.path()
+}
+
+// path is the pattern without its method, if it has one
+func (r Route) path() string {
+	if _, path, ok := strings.Cut(r.Pattern, " "); ok {
+		return path
+	}
+	return r.Pattern
+}
+
+// method is the pattern's method, or "" if it takes any
+func (r Route) method() string {
+	if method, _, ok := strings.Cut(r.Pattern, " "); ok {
+		return method
+	}
+	return ""
+}
+
+// Middleware wraps the handler of one route
+type Middleware func(route Route, next http.Handler) http.Handler
+
+// Register adds each route to mux wrapped in middleware, the first outermost.
+// Requests for unknown paths get a 404, and other methods on a path routed
+// by method a 405, through the same middleware.
+func Register(mux *http.ServeMux, routes []Route, middleware ...Middleware) {
+	all := append(routes, Route{Pattern: "/", Handler: notFound, Read: auth.RoleNone, Write: auth.RoleNone})
+
+	// Without these the catch-all would answer 404 for the wrong method
+	allowed := make(map[string][]string)
+	var paths []string
+	for _, route := range routes {
+		if method := route.method(); method != "" {
+			if allowed[route.path()] == nil {
+				paths = append(paths, route.path())
+			}
+			allowed[route.path()] = append(allowed[route.path()], method)
+			if method == http.MethodGet {
+				allowed[route.path()] = append(allowed[route.path()], http.MethodHead)
+			}
+		}
+	}
+	for _, path := range paths {
+		methods := allowed[path]
+		all = append(all, Route{Pattern: path, Read: auth.RoleNone, Write: auth.RoleNone, Handler: func(w http.ResponseWriter, r *http.Request) {
+			methodNotAllowed(w, r, methods...)
+		}})
+	}
+
+	for _, route := range all {
+		var h http.Handler = route.Handler
+		for i := len(middleware) - 1; i >= 0; i-- {
+			h = middleware[i](route, h)
+		}
+		mux.Handle(route.Pattern, h)
+	}
+}
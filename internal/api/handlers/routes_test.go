@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"service-matrix-go/internal/auth"
+	"service-matrix-go/internal/config"
+	"service-matrix-go/internal/metrics"
+)
+
+// specOperation is the part of an OpenAPI operation the test compares
+type specOperation struct {
+	Role string `json:"x-role"`
+}
+
+func loadSpec(t *testing.T) map[string]map[string]specOperation {
+	t.Helper()
+	var spec struct {
+		OpenAPI string                              `json:"openapi"`
+		Paths   map[string]map[string]specOperation `json:"paths"`
+	}
+	if err := json.Unmarshal(openAPISpec, &spec); err != nil {
+		t.Fatalf("openapi.json: %v", err)
+	}
+	if !strings.HasPrefix(spec.OpenAPI, "3.") {
+		t.Fatalf("openapi.json is version %q, want 3.x", spec.OpenAPI)
+	}
+	return spec.Paths
+}
+
+func testRoutes() []Route {
+	return Routes(&HTTPHandlers{}, config.Default(), metrics.NewRegistry())
+}
+
+// roleFor is the role a route needs for method
+func roleFor(route Route, method string) auth.Role {
+	if method == http.MethodGet || method == http.MethodHead {
+		return route.Read
+	}
+	return route.Write
+}
+
+func TestEveryRouteIsDocumented(t *testing.T) {
+	paths := loadSpec(t)
+	for _, route := range testRoutes() {
+		ops, ok := paths[route.path()]
+		if !ok {
+			t.Errorf("%s is not in openapi.json", route.Pattern)
+			continue
+		}
+
+		methods := []string{route.method()}
+		if methods[0] == "" {
+			// A route without a method documents the ones its handler takes
+			methods = nil
+			for method := range ops {
+				methods = append(methods, strings.ToUpper(method))
+			}
+			if len(methods) == 0 {
+				t.Errorf("%s has no operations in openapi.json", route.Pattern)
+			}
+		}
+		for _, method := range methods {
+			op, ok := ops[strings.ToLower(method)]
+			if !ok {
+				t.Errorf("%s %s is not in openapi.json", method, route.path())
+				continue
+			}
+			if want := roleFor(route, method); op.Role != string(want) {
+				t.Errorf("%s %s: openapi.json says role %q, the route needs %q", method, route.path(), op.Role, want)
+			}
+		}
+	}
+}
+
+func TestEveryDocumentedOperationIsRouted(t *testing.T) {
+	served := make(map[string]bool)
+	for _, route := range testRoutes() {
+		served[route.method()+" "+route.path()] = true
+	}
+
+	for path, ops := range loadSpec(t) {
+		for method := range ops {
+			method = strings.ToUpper(method)
+			if !served[method+" "+path] && !served[" "+path] {
+				t.Errorf("openapi.json documents %s %s, but no route serves it", method, path)
+			}
+		}
+	}
+}
+
+func TestSpecReferencesResolve(t *testing.T) {
+	var spec map[string]any
+	if err := json.Unmarshal(openAPISpec, &spec); err != nil {
+		t.Fatalf("openapi.json: %v", err)
+	}
+
+	var walk func(node any)
+	walk = func(node any) {
+		switch node := node.(type) {
+		case map[string]any:
+			if ref, ok := node["$ref"].(string); ok {
+				if !resolves(spec, ref) {
+					t.Errorf("openapi.json: %s does not resolve", ref)
+				}
+			}
+			for _, child := range node {
+				walk(child)
+			}
+		case []any:
+			for _, child := range node {
+				walk(child)
+			}
+		}
+	}
+	walk(spec)
+}
+
+// resolves reports whether a local reference such as
+// #/components/schemas/Error names something in spec
+func resolves(spec map[string]any, ref string) bool {
+	name, ok := strings.CutPrefix(ref, "#/")
+	if !ok {
+		return false
+	}
+	var node any = spec
+	for _, key := range strings.Split(name, "/") {
+		m, ok := node.(map[string]any)
+		if !ok {
+			return false
+		}
+		if node, ok = m[key]; !ok {
+			return false
+		}
+	}
+	return true
+}
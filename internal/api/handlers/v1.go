@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"service-matrix-go/internal/core/domain"
+)
+
+// The /v1 endpoints are routed by method and path, so unlike the legacy
+// ones they don't check the method themselves.
+
+// GetWord endpoint: every place a word is found, exactly unless
+// ?exactMatch=false
+func (h *HTTPHandlers) GetWord(w http.ResponseWriter, r *http.Request) {
+	word := r.PathValue("word")
+	query := r.URL.Query()
+	exactMatch := query.Get("exactMatch") != "false"
+
+	res, err := h.service.LookupWord(r.Context(), query.Get("language"), word, exactMatch, query.Get("details") == "true")
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	if len(res) == 0 {
+		writeError(w, r, http.StatusNotFound, codeNotFound, "word", "no word matches "+word)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(domain.WordResponse{Word: word, Results: res})
+}
+
+// SolveBoard endpoint: Search, always answering with the detailed shape
+func (h *HTTPHandlers) SolveBoard(w http.ResponseWriter, r *http.Request) {
+	var req domain.SearchRequest
+	if !decodeJSON(w, r, &req) {
+		return
+	}
+
+	res, err := h.service.Search(r.Context(), req)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	if res == nil {
+		res = []domain.FoundWord{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(domain.SearchResponse{Words: res})
+}
+
+// PreviewDictionaryMerge endpoint: stages the includes that can be merged as
+// candidates for POST /v1/dictionary:merge, like /Words/Merge/Preview. It is a
+// POST because staging replaces the last preview's candidates.
+func (h *HTTPHandlers) PreviewDictionaryMerge(w http.ResponseWriter, r *http.Request) {
+	res, err := h.service.PreviewMerge(r.Context(), r.URL.Query().Get("language"))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// GetListWord endpoint: the word's entry in the include or exclude list
+func (h *HTTPHandlers) GetListWord(w http.ResponseWriter, r *http.Request) {
+	include, ok := listParam(w, r)
+	if !ok {
+		return
+	}
+
+	res, err := h.service.FindInList(r.Context(), r.URL.Query().Get("language"), include, r.PathValue("word"))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// AddListWord endpoint: submits the word for the include or exclude list,
+// like /Words/Update
+func (h *HTTPHandlers) AddListWord(w http.ResponseWriter, r *http.Request) {
+	include, ok := listParam(w, r)
+	if !ok {
+		return
+	}
+	var body domain.ListWordRequest
+	if !decodeOptionalJSON(w, r, &body) {
+		return
+	}
+	if body.Language == "" {
+		body.Language = r.URL.Query().Get("language")
+	}
+
+	res, err := h.moderation.Submit(r.Context(), domain.UpdateWordsRequest{
+		Words:     []string{r.PathValue("word")},
+		Include:   include,
+		Language:  body.Language,
+		Submitter: caller(r),
+		Reason:    body.Reason,
+	})
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// DeleteListWord endpoint: removes the word from the include or exclude list
+func (h *HTTPHandlers) DeleteListWord(w http.ResponseWriter, r *http.Request) {
+	include, ok := listParam(w, r)
+	if !ok {
+		return
+	}
+
+	word := r.PathValue("word")
+	res, err := h.service.RemoveWords(r.Context(), domain.RemoveWordsRequest{
+		Words:    []string{word},
+		Include:  include,
+		Language: r.URL.Query().Get("language"),
+	})
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	if res.RemovedCount == 0 {
+		writeError(w, r, http.StatusNotFound, codeNotFound, "word", word+" is not in the "+r.PathValue("list")+" list")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// listParam reads the {list} path value, which must be include or exclude
+func listParam(w http.ResponseWriter, r *http.Request) (include bool, ok bool) {
+	switch list := r.PathValue("list"); list {
+	case "include":
+		return true, true
+	case "exclude":
+		return false, true
+	default:
+		writeError(w, r, http.StatusNotFound, codeNotFound, "list", "no list named "+list+"; use include or exclude")
+		return false, false
+	}
+}
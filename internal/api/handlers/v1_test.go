@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"service-matrix-go/internal/config"
+	"service-matrix-go/internal/core/domain"
+	"service-matrix-go/internal/core/language"
+	"service-matrix-go/internal/core/services"
+	"service-matrix-go/internal/infrastructure/memory"
+	"service-matrix-go/internal/infrastructure/storage"
+	"service-matrix-go/internal/metrics"
+)
+
+// dictService is a word service over a Russian dictionary of dict in a
+// temp directory and in-memory lists
+func dictService(t *testing.T, dict string) (*services.WordService, *storage.FileHelper) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "resources"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "resources", "definitions.txt"), []byte(dict), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fh := storage.NewFileHelper(dir)
+	return services.NewWordService(fh, memory.NewWordRepository(), language.NewRegistry()), fh
+}
+
+// v1Server routes every endpoint, without auth or rate limits, over
+// dictService. Submissions are approved on the first vote.
+func v1Server(t *testing.T, dict string) http.Handler {
+	t.Helper()
+	service, _ := dictService(t, dict)
+	moderation := services.NewModerationService(service, memory.NewSubmissionRepository(), 1)
+	h := NewHTTPHandlers(service, moderation, nil, nil, nil)
+
+	mux := http.NewServeMux()
+	Register(mux, Routes(h, config.Default(), metrics.NewRegistry()))
+	return RequestInfo(mux)
+}
+
+// call sends a request to handler and decodes a JSON reply into res, if
+// res isn't nil
+func call(t *testing.T, handler http.Handler, method, target, body string, res any) *httptest.ResponseRecorder {
+	t.Helper()
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	if res != nil && w.Code < http.StatusBadRequest {
+		if err := json.Unmarshal(w.Body.Bytes(), res); err != nil {
+			t.Fatalf("%s %s: decoding %q: %v", method, target, w.Body, err)
+		}
+	}
+	return w
+}
+
+// wantStatus checks the reply's status and, for an error, that it is the
+// error envelope with code
+func wantStatus(t *testing.T, w *httptest.ResponseRecorder, status int, code string) {
+	t.Helper()
+	if w.Code != status {
+		t.Fatalf("status %d, want %d: %s", w.Code, status, w.Body)
+	}
+	if status < http.StatusBadRequest {
+		return
+	}
+	var res domain.ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("decoding error %q: %v", w.Body, err)
+	}
+	if res.Error.Code != code || res.Error.RequestID == "" {
+		t.Errorf("error %+v, want code %s with a request ID", res.Error, code)
+	}
+}
+
+func TestV1GetWord(t *testing.T) {
+	srv := v1Server(t, "кот\nкотёл\n")
+
+	var res domain.WordResponse
+	wantStatus(t, call(t, srv, http.MethodGet, "/v1/words/кот", "", &res), http.StatusOK, "")
+	if res.Word != "кот" || len(res.Results) != 1 || res.Results[0].Source != "definitions.txt" {
+		t.Errorf("GET /v1/words/кот = %+v, want one match in definitions.txt", res)
+	}
+
+	res = domain.WordResponse{}
+	call(t, srv, http.MethodGet, "/v1/words/кот?exactMatch=false", "", &res)
+	if len(res.Results) != 2 {
+		t.Errorf("GET /v1/words/кот?exactMatch=false found %+v, want кот and котёл", res.Results)
+	}
+
+	wantStatus(t, call(t, srv, http.MethodGet, "/v1/words/дом", "", nil), http.StatusNotFound, codeNotFound)
+	wantStatus(t, call(t, srv, http.MethodGet, "/v1/words/кот?language=xx", "", nil), http.StatusBadRequest, codeUnknownLanguage)
+}
+
+func TestV1SolveBoard(t *testing.T) {
+	srv := v1Server(t, "кот\nток\nдом\n")
+
+	var res domain.SearchResponse
+	board := `{"lettersMatrix": [["к", "о"], ["#", "т"]], "maxWords": 10}`
+	wantStatus(t, call(t, srv, http.MethodPost, "/v1/boards:solve", board, &res), http.StatusOK, "")
+	var words []string
+	for _, w := range res.Words {
+		words = append(words, w.Word)
+		if len(w.Path) != 3 {
+			t.Errorf("%s has path %v, want three steps", w.Word, w.Path)
+		}
+		if w.Score != 4 {
+			t.Errorf("%s scores %d, want 4", w.Word, w.Score)
+		}
+	}
+	if slices.Sort(words); !slices.Equal(words, []string{"кот", "ток"}) {
+		t.Errorf("found %q, want кот and ток", words)
+	}
+
+	// Nothing found is an empty list, not null
+	w := call(t, srv, http.MethodPost, "/v1/boards:solve", `{"lettersMatrix": [["д"]]}`, nil)
+	if body := strings.TrimSpace(w.Body.String()); body != `{"words":[]}` {
+		t.Errorf("empty result %s, want {\"words\":[]}", body)
+	}
+
+	wantStatus(t, call(t, srv, http.MethodPost, "/v1/boards:solve", `{"lettersMatrix": []}`, nil), http.StatusBadRequest, codeInvalidRequest)
+	wantStatus(t, call(t, srv, http.MethodGet, "/v1/boards:solve", "", nil), http.StatusMethodNotAllowed, codeMethodNotAllowed)
+}
+
+func TestV1Lists(t *testing.T) {
+	srv := v1Server(t, "кот\n")
+
+	wantStatus(t, call(t, srv, http.MethodGet, "/v1/lists/include/лес", "", nil), http.StatusNotFound, codeNotFound)
+	wantStatus(t, call(t, srv, http.MethodPost, "/v1/lists/include/лес", `{"reason": "a forest"}`, nil), http.StatusOK, "")
+
+	var entry domain.ListWordResponse
+	wantStatus(t, call(t, srv, http.MethodGet, "/v1/lists/include/ЛЕС", "", &entry), http.StatusOK, "")
+	if entry != (domain.ListWordResponse{List: "include", Language: "ru", Word: "лес"}) {
+		t.Errorf("GET /v1/lists/include/ЛЕС = %+v, want лес in the ru include list", entry)
+	}
+	wantStatus(t, call(t, srv, http.MethodGet, "/v1/lists/exclude/лес", "", nil), http.StatusNotFound, codeNotFound)
+
+	var removed domain.RemoveWordsResponse
+	wantStatus(t, call(t, srv, http.MethodDelete, "/v1/lists/include/лес", "", &removed), http.StatusOK, "")
+	if removed.RemovedCount != 1 {
+		t.Errorf("DELETE removed %+v, want лес", removed)
+	}
+	wantStatus(t, call(t, srv, http.MethodDelete, "/v1/lists/include/лес", "", nil), http.StatusNotFound, codeNotFound)
+
+	wantStatus(t, call(t, srv, http.MethodGet, "/v1/lists/other/лес", "", nil), http.StatusNotFound, codeNotFound)
+	wantStatus(t, call(t, srv, http.MethodPost, "/v1/lists/include/лес", `{"reason": `, nil), http.StatusBadRequest, codeInvalidRequest)
+	wantStatus(t, call(t, srv, http.MethodPut, "/v1/lists/include/лес", "", nil), http.StatusMethodNotAllowed, codeMethodNotAllowed)
+}
+
+func TestV1DictionaryMerge(t *testing.T) {
+	srv := v1Server(t, "кошка\n")
+	for _, word := range []string{"лесник", "кошка", "ель", "мышка"} {
+		wantStatus(t, call(t, srv, http.MethodPost, "/v1/lists/include/"+word, "", nil), http.StatusOK, "")
+	}
+
+	// Without a preview there is nothing to merge
+	var merged domain.MergeResponse
+	wantStatus(t, call(t, srv, http.MethodPost, "/v1/dictionary:merge", "", &merged), http.StatusOK, "")
+	if merged.AddedCount != 0 {
+		t.Fatalf("merge before a preview added %q", merged.Added)
+	}
+
+	var preview domain.MergePreviewResponse
+	wantStatus(t, call(t, srv, http.MethodPost, "/v1/dictionary:merge-preview", "", &preview), http.StatusOK, "")
+	if !slices.Equal(preview.Candidates, []string{"лесник", "мышка"}) {
+		t.Errorf("preview candidates %q, want лесник and мышка", preview.Candidates)
+	}
+	reasons := make(map[string]string)
+	for _, r := range preview.Rejected {
+		reasons[r.Word] = r.Reason
+	}
+	if reasons["кошка"] != domain.MergeReasonAlreadyPresent || reasons["ель"] != domain.MergeReasonTooShort {
+		t.Errorf("preview rejected %+v, want кошка already present and ель too short", preview.Rejected)
+	}
+
+	// The merge takes what the preview staged, narrowed by the body
+	merged = domain.MergeResponse{}
+	wantStatus(t, call(t, srv, http.MethodPost, "/v1/dictionary:merge", `{"words": ["мышка"]}`, &merged), http.StatusOK, "")
+	if !slices.Equal(merged.Added, []string{"мышка"}) || merged.RemovedCount != 1 {
+		t.Errorf("merge = %+v, want мышка added and removed from the includes", merged)
+	}
+
+	var res domain.WordResponse
+	call(t, srv, http.MethodGet, "/v1/words/мышка", "", &res)
+	if len(res.Results) != 1 || res.Results[0].Source != "merged.txt" {
+		t.Errorf("after the merge мышка is in %+v, want merged.txt only", res.Results)
+	}
+
+	wantStatus(t, call(t, srv, http.MethodGet, "/v1/dictionary:merge-preview", "", nil), http.StatusMethodNotAllowed, codeMethodNotAllowed)
+	wantStatus(t, call(t, srv, http.MethodPost, "/v1/dictionary:merge-preview?language=xx", "", nil), http.StatusBadRequest, codeUnknownLanguage)
+}
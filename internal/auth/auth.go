@@ -0,0 +1,112 @@
+// Package auth holds the API keys callers identify themselves with and the
+// roles those keys grant
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Role is what a key may do; each role may do everything the ones below it may
+type Role string
+
+const (
+	// RoleNone grants nothing; it is only used for callers without a key
+	RoleNone Role = "none"
+	// RoleReader may search, look words up and read lists
+	RoleReader Role = "reader"
+	// RoleContributor may also submit words for moderation
+	RoleContributor Role = "contributor"
+	// RoleAdmin may also change the lists, merge, moderate and roll back
+	RoleAdmin Role = "admin"
+)
+
+var roleLevels = map[Role]int{RoleNone: 0, RoleReader: 1, RoleContributor: 2, RoleAdmin: 3}
+
+// ParseRole checks s names a role
+func ParseRole(s string) (Role, error) {
+	if _, ok := roleLevels[Role(s)]; !ok {
+		return "", fmt.Errorf("unknown role %q, want none, reader, contributor or admin", s)
+	}
+	return Role(s), nil
+}
+
+// Allows reports whether r covers need
+func (r Role) Allows(need Role) bool {
+	return roleLevels[r] >= roleLevels[need]
+}
+
+// minKeyLength keeps keys long enough not to be guessed
+const minKeyLength = 16
+
+// Key is one API key. Name identifies the caller in logs and the audit log.
+type Key struct {
+	Name string `json:"name"`
+	Key  string `json:"key"`
+	Role Role   `json:"role"`
+}
+
+// Keyring finds keys by their secret. Keys are held by hash so that a lookup
+// takes the same time however much of a guess is right.
+type Keyring struct {
+	keys   map[[sha256.Size]byte]Key
+	byName map[string]Key
+}
+
+// NewKeyring checks keys and indexes them
+func NewKeyring(keys []Key) (*Keyring, error) {
+	k := &Keyring{keys: make(map[[sha256.Size]byte]Key, len(keys)), byName: make(map[string]Key, len(keys))}
+	for _, key := range keys {
+		if key.Name == "" {
+			return nil, fmt.Errorf("API key without a name")
+		}
+		if _, ok := k.byName[key.Name]; ok {
+			return nil, fmt.Errorf("API key name %q is used twice", key.Name)
+		}
+		if len(key.Key) < minKeyLength {
+			return nil, fmt.Errorf("API key %q is shorter than %d characters", key.Name, minKeyLength)
+		}
+		if _, err := ParseRole(string(key.Role)); err != nil || key.Role == RoleNone {
+			return nil, fmt.Errorf("API key %q: role must be reader, contributor or admin, got %q", key.Name, key.Role)
+		}
+		hash := sha256.Sum256([]byte(key.Key))
+		if _, ok := k.keys[hash]; ok {
+			return nil, fmt.Errorf("API key %q has the same secret as another key", key.Name)
+		}
+		k.keys[hash] = key
+		k.byName[key.Name] = key
+	}
+	return k, nil
+}
+
+// Lookup returns the key with the given secret
+func (k *Keyring) Lookup(secret string) (Key, bool) {
+	key, ok := k.keys[sha256.Sum256([]byte(secret))]
+	return key, ok
+}
+
+// Named returns the key with the given name
+func (k *Keyring) Named(name string) (Key, bool) {
+	key, ok := k.byName[name]
+	return key, ok
+}
+
+// Len returns the number of keys
+func (k *Keyring) Len() int {
+	return len(k.keys)
+}
+
+// LoadKeysFile reads a JSON array of keys
+func LoadKeysFile(path string) ([]Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var keys []Key
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("keys file %s: %w", path, err)
+	}
+	return keys, nil
+}
@@ -0,0 +1,426 @@
+// Package config holds the server's settings. They come from built-in
+// defaults, an optional JSON config file, environment variables and command
+// line flags, each overriding the one before.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"service-matrix-go/internal/auth"
+	"service-matrix-go/internal/core/domain"
+	"service-matrix-go/internal/core/language"
+	"service-matrix-go/internal/core/ports"
+	"service-matrix-go/internal/infrastructure/dictindex"
+	"service-matrix-go/internal/logging"
+	"service-matrix-go/internal/ratelimit"
+)
+
+// Config is the complete server configuration
+type Config struct {
+	// Addr is the address the HTTP server listens on
+	Addr   string `json:"addr"`
+	Server Server `json:"server"`
+	// BaseDir is the root every other directory is relative to
+	BaseDir string `json:"baseDir"`
+	// ResourceDir holds the dictionaries, DataDir the word lists and logs
+	ResourceDir string `json:"resourceDir"`
+	DataDir     string `json:"dataDir"`
+	// LanguagesDir holds extra language packs, <resourceDir>/languages when empty
+	LanguagesDir string `json:"languagesDir,omitempty"`
+
+	Files      Files      `json:"files"`
+	CORS       CORS       `json:"cors"`
+	Limits     Limits     `json:"limits"`
+	Search     Search     `json:"search"`
+	Store      Store      `json:"store"`
+	Moderation Moderation `json:"moderation"`
+	Snapshots  Snapshots  `json:"snapshots"`
+	Follower   Follower   `json:"follower"`
+	Auth       Auth       `json:"auth"`
+	RateLimits RateLimits `json:"rateLimits"`
+	Log        Log        `json:"log"`
+
+	// DictionaryIndex is how compiled dictionaries are loaded: mmap, read or off
+	DictionaryIndex string `json:"dictionaryIndex"`
+}
+
+// Server holds the HTTP server's timeouts. On SIGTERM in-flight requests
+// get ShutdownTimeout to finish.
+type Server struct {
+	ReadTimeout     Duration `json:"readTimeout"`
+	WriteTimeout    Duration `json:"writeTimeout"`
+	IdleTimeout     Duration `json:"idleTimeout"`
+	ShutdownTimeout Duration `json:"shutdownTimeout"`
+}
+
+// Files names the files inside the resource and data directories
+type Files struct {
+	Dictionary    string `json:"dictionary"`
+	Frequencies   string `json:"frequencies"`
+	Include       string `json:"include"`
+	Exclude       string `json:"exclude"`
+	Merged        string `json:"merged"`
+	Mergeable     string `json:"mergeable"`
+	MergedCleaned string `json:"mergedCleaned"`
+	Submissions   string `json:"submissions"`
+	Audit         string `json:"audit"`
+	Changes       string `json:"changes"`
+	FollowerState string `json:"followerState"`
+	// Snapshots is a directory inside the data directory
+	Snapshots string `json:"snapshots"`
+}
+
+// CORS lists the origins browsers may call the API from; "*" allows any
+type CORS struct {
+	Origins []string `json:"origins"`
+}
+
+// Limits bound what a single request may ask for
+type Limits struct {
+	MaxRequestBytes int64 `json:"maxRequestBytes"`
+	MaxBoardRows    int   `json:"maxBoardRows"`
+	MaxBoardCols    int   `json:"maxBoardCols"`
+}
+
+// Search fills in the options a search request leaves out
+type Search struct {
+	MaxWords  int    `json:"maxWords"`
+	SortBy    string `json:"sortBy"`
+	MaxRarity string `json:"maxRarity"`
+}
+
+// Store picks the word list backend: file, memory or kv
+type Store struct {
+	Backend string `json:"backend"`
+	// Path is the kv store file, <dataDir>/words.db when empty
+	Path string `json:"path,omitempty"`
+}
+
+// Moderation approves a pending word once it has AutoApproveVotes submitters
+type Moderation struct {
+	AutoApproveVotes int `json:"autoApproveVotes"`
+}
+
+// Snapshots keeps the newest Retention snapshots per language (0 keeps all)
+type Snapshots struct {
+	Retention  int  `json:"retention"`
+	OnMutation bool `json:"onMutation"`
+}
+
+// Follower makes the instance poll Leader's changes feed every Interval,
+// sending Key if the leader requires one
+type Follower struct {
+	Leader   string   `json:"leader,omitempty"`
+	Key      string   `json:"key,omitempty"`
+	Interval Duration `json:"interval"`
+}
+
+// RateLimits are per client, by API key name or else address. A zero rate
+// or MaxConcurrentSearches turns that limit off.
+type RateLimits struct {
+	Search                ratelimit.Rate `json:"search"`
+	Mutation              ratelimit.Rate `json:"mutation"`
+	MaxConcurrentSearches int            `json:"maxConcurrentSearches"`
+}
+
+// Log sets the lowest level logged (debug, info, warn or error) and the
+// format (text or json)
+type Log struct {
+	Level  string `json:"level"`
+	Format string `json:"format"`
+}
+
+// Auth lists the API keys, inline and in KeysFile. Callers without a key get
+// AnonymousRole, even when there are no keys at all.
+type Auth struct {
+	Keys          []auth.Key `json:"keys,omitempty"`
+	KeysFile      string     `json:"keysFile,omitempty"`
+	AnonymousRole string     `json:"anonymousRole"`
+}
+
+// Default returns the settings the server has always run with
+func Default() Config {
+	return Config{
+		Addr: ":8080",
+		Server: Server{
+			ReadTimeout:     Duration{15 * time.Second},
+			WriteTimeout:    Duration{60 * time.Second},
+			IdleTimeout:     Duration{120 * time.Second},
+			ShutdownTimeout: Duration{30 * time.Second},
+		},
+		ResourceDir: "resources",
+		DataDir:     "data",
+		Files: Files{
+			Dictionary:    "definitions.txt",
+			Frequencies:   "frequencies.txt",
+			Include:       "include.txt",
+			Exclude:       "exclude.txt",
+			Merged:        "merged.txt",
+			Mergeable:     "mergeable_definitions.txt",
+			MergedCleaned: "merged_cleaned.txt",
+			Submissions:   "submissions.jsonl",
+			Audit:         "audit.jsonl",
+			Changes:       "changes.jsonl",
+			FollowerState: "follower_version.txt",
+			Snapshots:     "snapshots",
+		},
+		CORS: CORS{Origins: []string{"*"}},
+		Limits: Limits{
+			MaxRequestBytes: 1 << 20,
+			MaxBoardRows:    50,
+			MaxBoardCols:    50,
+		},
+		Search: Search{
+			MaxWords: 100,
+			SortBy:   domain.SortByLength,
+		},
+		Store:      Store{Backend: "file"},
+		Moderation: Moderation{AutoApproveVotes: 3},
+		Snapshots:  Snapshots{Retention: 20, OnMutation: true},
+		Follower:   Follower{Interval: Duration{5 * time.Second}},
+		Auth:       Auth{AnonymousRole: string(auth.RoleReader)},
+		RateLimits: RateLimits{
+			Search:                ratelimit.Rate{PerSecond: 10, Burst: 20},
+			Mutation:              ratelimit.Rate{PerSecond: 1, Burst: 5},
+			MaxConcurrentSearches: 2 * runtime.NumCPU(),
+		},
+		Log:             Log{Level: "info", Format: logging.FormatText},
+		DictionaryIndex: string(dictindex.ModeMmap),
+	}
+}
+
+// Layout places the built-in language packs in the configured directories
+func (c Config) Layout() language.Layout {
+	return language.Layout{
+		ResourceDir:    c.ResourceDir,
+		DataDir:        c.DataDir,
+		DictionaryFile: c.Files.Dictionary,
+		FrequencyFile:  c.Files.Frequencies,
+	}
+}
+
+// LanguagesPath returns the directory extra language packs are loaded from
+func (c Config) LanguagesPath() string {
+	if c.LanguagesDir != "" {
+		return filepath.Join(c.BaseDir, c.LanguagesDir)
+	}
+	return filepath.Join(c.BaseDir, c.ResourceDir, "languages")
+}
+
+// StorePath returns the kv store file
+func (c Config) StorePath() string {
+	if c.Store.Path != "" {
+		return c.Store.Path
+	}
+	return filepath.Join(c.BaseDir, c.DataDir, "words.db")
+}
+
+// ListFiles maps each word list to its file name
+func (c Config) ListFiles() map[ports.ListName]string {
+	return map[ports.ListName]string{
+		ports.ListInclude:       c.Files.Include,
+		ports.ListExclude:       c.Files.Exclude,
+		ports.ListMerged:        c.Files.Merged,
+		ports.ListMergeable:     c.Files.Mergeable,
+		ports.ListMergedCleaned: c.Files.MergedCleaned,
+	}
+}
+
+// Validate reports every problem with c at once
+func (c Config) Validate() error {
+	var errs []error
+	fail := func(field, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: "+format, append([]any{field}, args...)...))
+	}
+
+	if _, _, err := net.SplitHostPort(c.Addr); err != nil {
+		fail("addr", "%v", err)
+	}
+	for _, timeout := range []struct {
+		name  string
+		value Duration
+	}{
+		{"server.readTimeout", c.Server.ReadTimeout}, {"server.writeTimeout", c.Server.WriteTimeout},
+		{"server.idleTimeout", c.Server.IdleTimeout}, {"server.shutdownTimeout", c.Server.ShutdownTimeout},
+	} {
+		if timeout.value.Duration <= 0 {
+			fail(timeout.name, "must be positive")
+		}
+	}
+	if info, err := os.Stat(c.BaseDir); err != nil {
+		fail("baseDir", "%v", err)
+	} else if !info.IsDir() {
+		fail("baseDir", "%s is not a directory", c.BaseDir)
+	}
+	for _, dir := range []namedValue{{"resourceDir", c.ResourceDir}, {"dataDir", c.DataDir}, {"languagesDir", c.LanguagesDir}} {
+		if filepath.IsAbs(dir.value) {
+			fail(dir.name, "must be relative to baseDir, got %q", dir.value)
+		}
+	}
+	if c.ResourceDir == "" {
+		fail("resourceDir", "must not be empty")
+	}
+	if c.DataDir == "" {
+		fail("dataDir", "must not be empty")
+	}
+
+	for _, file := range []namedValue{
+		{"files.dictionary", c.Files.Dictionary}, {"files.frequencies", c.Files.Frequencies},
+		{"files.include", c.Files.Include}, {"files.exclude", c.Files.Exclude},
+		{"files.merged", c.Files.Merged}, {"files.mergeable", c.Files.Mergeable},
+		{"files.mergedCleaned", c.Files.MergedCleaned}, {"files.submissions", c.Files.Submissions},
+		{"files.audit", c.Files.Audit}, {"files.changes", c.Files.Changes},
+		{"files.followerState", c.Files.FollowerState}, {"files.snapshots", c.Files.Snapshots},
+	} {
+		if name := file.value; name == "" || name != filepath.Base(name) || name == "." || name == ".." {
+			fail(file.name, "must be a plain file name, got %q", name)
+		}
+	}
+
+	if len(c.CORS.Origins) == 0 {
+		fail("cors.origins", "must list at least one origin or \"*\"")
+	}
+	for _, origin := range c.CORS.Origins {
+		if origin == "*" {
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Path != "" {
+			fail("cors.origins", "%q is not an origin like https://example.com", origin)
+		}
+	}
+
+	if c.Limits.MaxRequestBytes <= 0 {
+		fail("limits.maxRequestBytes", "must be positive")
+	}
+	if c.Limits.MaxBoardRows <= 0 {
+		fail("limits.maxBoardRows", "must be positive")
+	}
+	if c.Limits.MaxBoardCols <= 0 {
+		fail("limits.maxBoardCols", "must be positive")
+	}
+
+	if c.Search.MaxWords <= 0 {
+		fail("search.maxWords", "must be positive")
+	}
+	switch c.Search.SortBy {
+	case domain.SortByLength, domain.SortByFrequency:
+	default:
+		fail("search.sortBy", "must be %q or %q, got %q", domain.SortByLength, domain.SortByFrequency, c.Search.SortBy)
+	}
+	if _, err := domain.ParseTier(c.Search.MaxRarity); err != nil {
+		fail("search.maxRarity", "%v", err)
+	}
+
+	switch c.Store.Backend {
+	case "file", "memory", "kv":
+	default:
+		fail("store.backend", "must be file, memory or kv, got %q", c.Store.Backend)
+	}
+	if c.Moderation.AutoApproveVotes < 1 {
+		fail("moderation.autoApproveVotes", "must be at least 1")
+	}
+	if c.Snapshots.Retention < 0 {
+		fail("snapshots.retention", "must not be negative")
+	}
+	if c.Follower.Leader != "" {
+		if u, err := url.Parse(c.Follower.Leader); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			fail("follower.leader", "must be an http(s) URL")
+		}
+		if c.Follower.Interval.Duration <= 0 {
+			fail("follower.interval", "must be positive")
+		}
+	}
+	if _, err := auth.ParseRole(c.Auth.AnonymousRole); err != nil {
+		fail("auth.anonymousRole", "%v", err)
+	}
+	if _, err := auth.NewKeyring(c.Auth.Keys); err != nil {
+		fail("auth.keys", "%v", err)
+	}
+	for _, rate := range []struct {
+		name string
+		rate ratelimit.Rate
+	}{{"rateLimits.search", c.RateLimits.Search}, {"rateLimits.mutation", c.RateLimits.Mutation}} {
+		if rate.rate.PerSecond < 0 || rate.rate.Burst < 0 {
+			fail(rate.name, "must not be negative")
+		}
+	}
+	if c.RateLimits.MaxConcurrentSearches < 0 {
+		fail("rateLimits.maxConcurrentSearches", "must not be negative")
+	}
+	if _, err := logging.New(io.Discard, c.Log.Level, c.Log.Format); err != nil {
+		fail("log", "%v", err)
+	}
+	if _, err := dictindex.ParseMode(c.DictionaryIndex); err != nil {
+		fail("dictionaryIndex", "%v", err)
+	}
+	return errors.Join(errs...)
+}
+
+// namedValue pairs a setting's name with its value, in the order they are checked
+type namedValue struct {
+	name, value string
+}
+
+// redactedSecret replaces secrets in Redacted
+const redactedSecret = "xxxxx"
+
+// Redacted returns a copy of c that is safe to show: credentials are masked.
+// Secret settings added later must be masked here too.
+func (c Config) Redacted() Config {
+	if u, err := url.Parse(c.Follower.Leader); err == nil && u.User != nil {
+		c.Follower.Leader = u.Redacted()
+	}
+	if c.Follower.Key != "" {
+		c.Follower.Key = redactedSecret
+	}
+	c.CORS.Origins = append([]string(nil), c.CORS.Origins...)
+	keys := make([]auth.Key, len(c.Auth.Keys))
+	for i, key := range c.Auth.Keys {
+		key.Key = redactedSecret
+		keys[i] = key
+	}
+	c.Auth.Keys = keys
+	return c
+}
+
+// KeysPath returns the keys file, or "" when there is none
+func (c Config) KeysPath() string {
+	if c.Auth.KeysFile == "" || filepath.IsAbs(c.Auth.KeysFile) {
+		return c.Auth.KeysFile
+	}
+	return filepath.Join(c.BaseDir, c.Auth.KeysFile)
+}
+
+// Duration is a time.Duration written as "5s" in JSON
+type Duration struct {
+	time.Duration
+}
+
+// MarshalJSON implements json.Marshaler
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON accepts a duration string such as "1m30s"
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"5s\": %w", err)
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	d.Duration = v
+	return nil
+}
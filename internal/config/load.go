@@ -0,0 +1,203 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// setting is one option that can be set by environment variable and flag
+type setting struct {
+	flag  string
+	env   string
+	usage string
+	value flag.Value
+}
+
+// settings binds every environment variable and flag to its field in c
+func settings(c *Config) []setting {
+	return []setting{
+		{"addr", "LISTEN_ADDR", "address to listen on", (*stringValue)(&c.Addr)},
+		{"read-timeout", "SERVER_READ_TIMEOUT", "longest time to read a request", (*durationValue)(&c.Server.ReadTimeout.Duration)},
+		{"write-timeout", "SERVER_WRITE_TIMEOUT", "longest time to handle a request and write the response", (*durationValue)(&c.Server.WriteTimeout.Duration)},
+		{"idle-timeout", "SERVER_IDLE_TIMEOUT", "how long idle keep-alive connections stay open", (*durationValue)(&c.Server.IdleTimeout.Duration)},
+		{"shutdown-timeout", "SERVER_SHUTDOWN_TIMEOUT", "how long in-flight requests get to finish on SIGTERM", (*durationValue)(&c.Server.ShutdownTimeout.Duration)},
+		{"base-dir", "BASE_DIR", "root directory, the working directory by default", (*stringValue)(&c.BaseDir)},
+		{"resource-dir", "RESOURCE_DIR", "dictionary directory, relative to the base directory", (*stringValue)(&c.ResourceDir)},
+		{"data-dir", "DATA_DIR", "word list directory, relative to the base directory", (*stringValue)(&c.DataDir)},
+		{"languages-dir", "LANGUAGES_DIR", "language pack directory, relative to the base directory", (*stringValue)(&c.LanguagesDir)},
+		{"cors-origins", "CORS_ORIGINS", "comma-separated origins allowed to call the API, * for any", (*listValue)(&c.CORS.Origins)},
+		{"max-request-bytes", "MAX_REQUEST_BYTES", "largest request body accepted", (*int64Value)(&c.Limits.MaxRequestBytes)},
+		{"max-board-rows", "MAX_BOARD_ROWS", "most rows a search board may have", (*intValue)(&c.Limits.MaxBoardRows)},
+		{"max-board-cols", "MAX_BOARD_COLS", "most columns a search board may have", (*intValue)(&c.Limits.MaxBoardCols)},
+		{"search-max-words", "SEARCH_MAX_WORDS", "results returned when a search doesn't set maxWords", (*intValue)(&c.Search.MaxWords)},
+		{"search-sort-by", "SEARCH_SORT_BY", "search order when a search doesn't set sortBy", (*stringValue)(&c.Search.SortBy)},
+		{"search-max-rarity", "SEARCH_MAX_RARITY", "rarity cutoff when a search doesn't set maxRarity", (*stringValue)(&c.Search.MaxRarity)},
+		{"word-store", "WORD_STORE", "word list backend: file, memory or kv", (*stringValue)(&c.Store.Backend)},
+		{"word-store-path", "WORD_STORE_PATH", "kv store file", (*stringValue)(&c.Store.Path)},
+		{"auto-approve-votes", "MODERATION_AUTO_APPROVE_VOTES", "submitters needed to approve a word automatically", (*intValue)(&c.Moderation.AutoApproveVotes)},
+		{"snapshot-retention", "SNAPSHOT_RETENTION", "snapshots kept per language, 0 keeps all", (*intValue)(&c.Snapshots.Retention)},
+		{"snapshot-on-mutation", "SNAPSHOT_ON_MUTATION", "snapshot after every word list change", (*boolValue)(&c.Snapshots.OnMutation)},
+		{"follow-leader", "FOLLOW_LEADER", "URL of an instance whose changes feed to follow", (*stringValue)(&c.Follower.Leader)},
+		{"follow-key", "FOLLOW_KEY", "API key to send to the leader", (*stringValue)(&c.Follower.Key)},
+		{"follow-interval", "FOLLOW_INTERVAL", "how often to poll the leader", (*durationValue)(&c.Follower.Interval.Duration)},
+		{"api-keys-file", "API_KEYS_FILE", "JSON file of API keys", (*stringValue)(&c.Auth.KeysFile)},
+		{"anonymous-role", "AUTH_ANONYMOUS_ROLE", "role of callers without a key: none, reader, contributor or admin", (*stringValue)(&c.Auth.AnonymousRole)},
+		{"search-rate", "RATE_LIMIT_SEARCH", "searches per second per client, 0 for no limit", (*float64Value)(&c.RateLimits.Search.PerSecond)},
+		{"search-burst", "RATE_LIMIT_SEARCH_BURST", "searches a client may make at once", (*intValue)(&c.RateLimits.Search.Burst)},
+		{"mutation-rate", "RATE_LIMIT_MUTATION", "word list changes per second per client, 0 for no limit", (*float64Value)(&c.RateLimits.Mutation.PerSecond)},
+		{"mutation-burst", "RATE_LIMIT_MUTATION_BURST", "word list changes a client may make at once", (*intValue)(&c.RateLimits.Mutation.Burst)},
+		{"max-concurrent-searches", "MAX_CONCURRENT_SEARCHES", "searches run at once across all clients, 0 for no limit", (*intValue)(&c.RateLimits.MaxConcurrentSearches)},
+		{"log-level", "LOG_LEVEL", "lowest level logged: debug, info, warn or error", (*stringValue)(&c.Log.Level)},
+		{"log-format", "LOG_FORMAT", "log format: text or json", (*stringValue)(&c.Log.Format)},
+		{"dictionary-index", "DICTIONARY_INDEX", "compiled dictionary loading: mmap, read or off", (*stringValue)(&c.DictionaryIndex)},
+	}
+}
+
+// Load builds the configuration from the defaults, the JSON file named by
+// -config or CONFIG_FILE, the environment and the flags in args, then
+// validates it. getenv is os.Getenv outside of tests.
+func Load(name string, args []string, getenv func(string) string) (Config, error) {
+	c := Default()
+	cwd, err := os.Getwd()
+	if err != nil {
+		return Config{}, err
+	}
+	c.BaseDir = cwd
+
+	// Flags win over everything, so remember them and apply them last
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	file := fs.String("config", getenv("CONFIG_FILE"), "JSON config file")
+	type assignment struct {
+		name  string
+		value flag.Value
+		raw   string
+	}
+	var flagged []assignment
+	all := settings(&c)
+	for _, s := range all {
+		s := s
+		fs.Func(s.flag, s.usage+" ($"+s.env+")", func(raw string) error {
+			flagged = append(flagged, assignment{"-" + s.flag, s.value, raw})
+			return nil
+		})
+	}
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+	if fs.NArg() > 0 {
+		return Config{}, fmt.Errorf("unexpected arguments %q", fs.Args())
+	}
+
+	if *file != "" {
+		if err := loadFile(&c, *file); err != nil {
+			return Config{}, err
+		}
+	}
+	for _, s := range all {
+		if raw := getenv(s.env); raw != "" {
+			if err := s.value.Set(raw); err != nil {
+				return Config{}, fmt.Errorf("%s: %w", s.env, err)
+			}
+		}
+	}
+	for _, a := range flagged {
+		if err := a.value.Set(a.raw); err != nil {
+			return Config{}, fmt.Errorf("%s: %w", a.name, err)
+		}
+	}
+
+	if c.BaseDir, err = filepath.Abs(c.BaseDir); err != nil {
+		return Config{}, err
+	}
+	if err := c.Validate(); err != nil {
+		return Config{}, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return c, nil
+}
+
+// loadFile overlays the settings in a JSON file; unknown keys are an error
+// so that a typo doesn't go unnoticed
+func loadFile(c *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(c); err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	return nil
+}
+
+type stringValue string
+
+func (v *stringValue) Set(s string) error { *v = stringValue(s); return nil }
+func (v *stringValue) String() string     { return string(*v) }
+
+type intValue int
+
+func (v *intValue) Set(s string) error {
+	n, err := strconv.Atoi(s)
+	*v = intValue(n)
+	return err
+}
+func (v *intValue) String() string { return strconv.Itoa(int(*v)) }
+
+type int64Value int64
+
+func (v *int64Value) Set(s string) error {
+	n, err := strconv.ParseInt(s, 10, 64)
+	*v = int64Value(n)
+	return err
+}
+func (v *int64Value) String() string { return strconv.FormatInt(int64(*v), 10) }
+
+type float64Value float64
+
+func (v *float64Value) Set(s string) error {
+	f, err := strconv.ParseFloat(s, 64)
+	*v = float64Value(f)
+	return err
+}
+func (v *float64Value) String() string { return strconv.FormatFloat(float64(*v), 'g', -1, 64) }
+
+type boolValue bool
+
+func (v *boolValue) Set(s string) error {
+	b, err := strconv.ParseBool(s)
+	*v = boolValue(b)
+	return err
+}
+func (v *boolValue) String() string { return strconv.FormatBool(bool(*v)) }
+
+type durationValue time.Duration
+
+func (v *durationValue) Set(s string) error {
+	d, err := time.ParseDuration(s)
+	*v = durationValue(d)
+	return err
+}
+func (v *durationValue) String() string { return time.Duration(*v).String() }
+
+// listValue is a comma-separated list; setting it replaces the whole list
+type listValue []string
+
+func (v *listValue) Set(s string) error {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	*v = list
+	return nil
+}
+func (v *listValue) String() string { return strings.Join(*v, ",") }
@@ -13,13 +13,12 @@ type WordSearchHelper struct {
 	arLettersStatic [][]string
 	arWord          []string
 	foundWord       map[int]map[string]string // index -> char -> "row col"
-	iFirstColumn    int
-	iFirstRow       int
 	sFoundString    strings.Builder
 	sWord           string
 }
 
-// NewWordSearchHelper creates a new helper instance
+// NewWordSearchHelper creates a new helper instance. Rows of arLetters may
+// differ in length, and an empty cell is a hole no letter can use.
 func NewWordSearchHelper(sWord string, arLetters [][]string) *WordSearchHelper {
 	helper := &WordSearchHelper{
 		sWord:           sWord,
@@ -144,80 +143,12 @@ func (h *WordSearchHelper) FindWord(iWordIndexWrapper *int, iMatrixStartWrapper
 		}
 	}
 
-	// The "GetNextFirstLetter" logic in C# is invoked if the standard loop fails
-	// This part handles backtracking/retrying from a different start point if the greedy approach failed?
-	// The C# code is quite specific. I'll attempt to simplify or map closely.
-
-	// Due to complexity and potential recursion in "GetNextFirstLetter" -> calling "FindWord(1, null, null)",
-	// creating a simplified version of standard backtracking might be safer than direct port if I don't fully replicate the state mutations.
-
-	// However, sticking to direct port for safety.
-	tempCol := h.iFirstColumn
-	tempRow := h.iFirstRow
-	h.UpdateNextFirstLetterStartPos()
-
-	// Check if we need to backtrack/retry
-	if !(tempCol == h.iFirstColumn && tempRow == h.iFirstRow) && h.GetNextFirstLetter(h.iFirstColumn, h.iFirstRow) {
-		one := 1
-		return h.FindWord(&one, nil, nil)
-	}
-
+	// The C# port retried from the next first letter of a 5x5 board here.
+	// Search already tries every location of the first letter, so that only
+	// repeated work, and broke on other board sizes.
 	return false
 }
 
-func (h *WordSearchHelper) GetNextFirstLetter(iMatrixStart, jMatrixStart int) bool {
-	i3Start := iMatrixStart
-	jStart := jMatrixStart
-
-	sSearchChar := h.arWord[0]
-	bFound := false
-	h.arLetters = CopyArray(h.arLettersStatic)
-	h.foundWord = make(map[int]map[string]string)
-	h.sFoundString.Reset()
-
-	for i3 := i3Start; i3 < len(h.arLetters) && !bFound; i3++ {
-		j := 0
-		if i3 == i3Start {
-			j = jStart
-		}
-		for ; j < len(h.arLetters[i3]); j++ {
-			if bFound {
-				break
-			}
-			if h.arLetters[i3][j] == sSearchChar {
-				if h.IsNeighborToNextLetter(i3, j, h.arWord, 0, CopyArray(h.arLetters)) {
-					h.sFoundString.WriteString(sSearchChar)
-					hLetLoc := map[string]string{
-						sSearchChar: strconv.Itoa(i3) + " " + strconv.Itoa(j),
-					}
-					h.foundWord[0] = hLetLoc
-					h.arLetters[i3][j] = "*"
-					bFound = true
-					break
-				}
-			}
-		}
-	}
-	return bFound
-}
-
-func (h *WordSearchHelper) UpdateNextFirstLetterStartPos() {
-	tempCol := h.iFirstColumn
-	tempRow := h.iFirstRow
-	if h.iFirstColumn < 5 && h.iFirstRow < 5 { // Hardcoded 5? Assuming matrix size constraint or logic from C#
-		h.iFirstRow++
-		if h.iFirstRow > 4 {
-			h.iFirstRow = h.iFirstRow % 4 // Original Code: _iFirstRow %= 4; wait, 5%4 is 1.
-			h.iFirstColumn++
-		}
-		// Original: if (_iFirstColumn > 4 || _iFirstRow > 4) -> reset
-		if h.iFirstColumn > 4 || h.iFirstRow > 4 {
-			h.iFirstColumn = tempCol
-			h.iFirstRow = tempRow
-		}
-	}
-}
-
 func (h *WordSearchHelper) IsNeighborToNextLetter(iCurrentX, iCurrentY int, arWord2 []string, iWordIndex int, arLettersLoc [][]string) bool {
 	if iWordIndex == len(arWord2)-1 || iWordIndex == 0 { // 0 check in C# IsNeighborToNextLetter seems to allow first letter?
 		// Actually original C# says: if (iWordIndex == arWord2.Length - 1 || iWordIndex == 0) return true;
@@ -229,13 +160,10 @@ func (h *WordSearchHelper) IsNeighborToNextLetter(iCurrentX, iCurrentY int, arWo
 			neighborX := (iCurrentX + dX) - 2
 			neighborY := (iCurrentY + dY) - 2
 
-			// Boundary checks
-			rows := len(arLettersLoc)
-			cols := len(arLettersLoc[0])
-
+			// Boundary checks; rows may differ in length
 			if !(neighborX == iCurrentX && neighborY == iCurrentY) &&
-				neighborX >= 0 && neighborX < rows &&
-				neighborY >= 0 && neighborY < cols &&
+				neighborX >= 0 && neighborX < len(arLettersLoc) &&
+				neighborY >= 0 && neighborY < len(arLettersLoc[neighborX]) &&
 				strings.EqualFold(sNextLetter, arLettersLoc[neighborX][neighborY]) {
 
 				secondNextNeighbor := true
@@ -255,8 +183,6 @@ func (h *WordSearchHelper) IsNeighborToNextLetter(iCurrentX, iCurrentY int, arWo
 
 func (h *WordSearchHelper) IsNeighborToPrevLetter(iCol, iRow, iWordIndex int, sLet string) bool {
 	if iWordIndex == 0 {
-		h.iFirstColumn = iCol
-		h.iFirstRow = iRow
 		return true
 	}
 	hLetterIdx, ok := h.foundWord[iWordIndex-1]
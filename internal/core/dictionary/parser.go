@@ -0,0 +1,86 @@
+package dictionary
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"service-matrix-go/internal/core/domain"
+	"service-matrix-go/internal/core/normalization"
+)
+
+// ParseLines turns dictionary file lines into entries. Each line may be in
+// any of the supported formats:
+//
+//	plain:  word
+//	TSV:    word<TAB>definition<TAB>part of speech<TAB>frequency rank<TAB>tag,tag
+//	JSONL:  {"word": "...", "definition": "...", "partOfSpeech": "...", "frequencyRank": 1, "tags": ["..."]}
+//
+// Trailing TSV columns may be omitted. Blank lines and lines starting with
+// "#" are skipped, as are lines that fail to parse.
+func ParseLines(lines []string) []domain.DictionaryEntry {
+	entries := make([]domain.DictionaryEntry, 0, len(lines))
+	for _, line := range lines {
+		if entry, ok := ParseLine(line); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// ParseLine parses a single dictionary line, see ParseLines for the formats.
+// The word is returned as written; callers normalise it.
+func ParseLine(line string) (domain.DictionaryEntry, bool) {
+	trimmed := strings.TrimSpace(normalization.StripBOM(line))
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return domain.DictionaryEntry{}, false
+	}
+
+	if strings.HasPrefix(trimmed, "{") {
+		var entry domain.DictionaryEntry
+		if err := json.Unmarshal([]byte(trimmed), &entry); err != nil || entry.Word == "" {
+			return domain.DictionaryEntry{}, false
+		}
+		return entry, true
+	}
+
+	if !strings.Contains(line, "\t") {
+		return domain.DictionaryEntry{Word: line}, true
+	}
+
+	fields := strings.Split(line, "\t")
+	entry := domain.DictionaryEntry{Word: fields[0]}
+	if len(fields) > 1 {
+		entry.Definition = strings.TrimSpace(fields[1])
+	}
+	if len(fields) > 2 {
+		entry.PartOfSpeech = strings.TrimSpace(fields[2])
+	}
+	if len(fields) > 3 {
+		entry.FrequencyRank, _ = strconv.Atoi(strings.TrimSpace(fields[3]))
+	}
+	if len(fields) > 4 {
+		for _, tag := range strings.Split(fields[4], ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				entry.Tags = append(entry.Tags, tag)
+			}
+		}
+	}
+	if strings.TrimSpace(entry.Word) == "" {
+		return domain.DictionaryEntry{}, false
+	}
+	return entry, true
+}
+
+// FormatTSV renders entry as a TSV dictionary line, dropping empty trailing columns
+func FormatTSV(entry domain.DictionaryEntry) string {
+	rank := ""
+	if entry.FrequencyRank > 0 {
+		rank = strconv.Itoa(entry.FrequencyRank)
+	}
+	fields := []string{entry.Word, entry.Definition, entry.PartOfSpeech, rank, strings.Join(entry.Tags, ",")}
+	for len(fields) > 1 && fields[len(fields)-1] == "" {
+		fields = fields[:len(fields)-1]
+	}
+	return strings.Join(fields, "\t")
+}
@@ -0,0 +1,110 @@
+package dictionary
+
+import (
+	"reflect"
+	"testing"
+
+	"service-matrix-go/internal/core/domain"
+)
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want domain.DictionaryEntry
+		ok   bool
+	}{
+		{"plain", "кот", domain.DictionaryEntry{Word: "кот"}, true},
+		{"plain kept as written", "Кот ", domain.DictionaryEntry{Word: "Кот "}, true},
+		{"plain phrase", "белый медведь", domain.DictionaryEntry{Word: "белый медведь"}, true},
+
+		{"TSV word only", "кот\t", domain.DictionaryEntry{Word: "кот"}, true},
+		{"TSV definition", "кот\tдомашнее животное", domain.DictionaryEntry{Word: "кот", Definition: "домашнее животное"}, true},
+		{"TSV every column", "кот\t животное \tnoun\t 120 \tанимал, быт,", domain.DictionaryEntry{
+			Word: "кот", Definition: "животное", PartOfSpeech: "noun", FrequencyRank: 120, Tags: []string{"анимал", "быт"},
+		}, true},
+		{"TSV skipped columns", "кот\t\t\t7", domain.DictionaryEntry{Word: "кот", FrequencyRank: 7}, true},
+		{"TSV rank that isn't a number", "кот\t\tnoun\tчасто", domain.DictionaryEntry{Word: "кот", PartOfSpeech: "noun"}, true},
+		{"TSV extra columns", "кот\tж\tnoun\t1\tt\tlater", domain.DictionaryEntry{Word: "кот", Definition: "ж", PartOfSpeech: "noun", FrequencyRank: 1, Tags: []string{"t"}}, true},
+
+		{"JSONL", `{"word": "кот", "definition": "животное", "partOfSpeech": "noun", "frequencyRank": 3, "tags": ["a", "b"]}`, domain.DictionaryEntry{
+			Word: "кот", Definition: "животное", PartOfSpeech: "noun", FrequencyRank: 3, Tags: []string{"a", "b"},
+		}, true},
+		{"JSONL word only", ` {"word": "кот"} `, domain.DictionaryEntry{Word: "кот"}, true},
+		{"JSONL with a BOM", "\uFEFF{\"word\": \"кот\"}", domain.DictionaryEntry{Word: "кот"}, true},
+
+		// Skipped lines
+		{"blank", "", domain.DictionaryEntry{}, false},
+		{"whitespace", " \t ", domain.DictionaryEntry{}, false},
+		{"BOM only", "\uFEFF", domain.DictionaryEntry{}, false},
+		{"comment", "# a comment", domain.DictionaryEntry{}, false},
+		{"indented comment", "  #кот", domain.DictionaryEntry{}, false},
+
+		// Malformed lines
+		{"TSV without a word", "\tживотное\tnoun", domain.DictionaryEntry{}, false},
+		{"TSV blank word", "  \tживотное", domain.DictionaryEntry{}, false},
+		{"JSONL cut short", `{"word": "кот"`, domain.DictionaryEntry{}, false},
+		{"JSONL without a word", `{"definition": "животное"}`, domain.DictionaryEntry{}, false},
+		{"JSONL wrong type", `{"word": "кот", "frequencyRank": "3"}`, domain.DictionaryEntry{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseLine(tt.line)
+			if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseLine(%q) = %+v, %v; want %+v, %v", tt.line, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestParseLines(t *testing.T) {
+	lines := []string{
+		"\uFEFF# words",
+		"кот",
+		"",
+		"дом\tздание",
+		`{"word": "лес", "tags": ["природа"]}`,
+		`{"word": `,
+		"\tбез слова",
+	}
+	want := []domain.DictionaryEntry{
+		{Word: "кот"},
+		{Word: "дом", Definition: "здание"},
+		{Word: "лес", Tags: []string{"природа"}},
+	}
+	if got := ParseLines(lines); !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseLines = %+v, want %+v", got, want)
+	}
+	if got := ParseLines(nil); got == nil || len(got) != 0 {
+		t.Errorf("ParseLines(nil) = %#v, want an empty list", got)
+	}
+}
+
+func TestFormatTSVRoundTrips(t *testing.T) {
+	tests := []struct {
+		entry domain.DictionaryEntry
+		line  string
+	}{
+		{domain.DictionaryEntry{Word: "кот"}, "кот"},
+		{domain.DictionaryEntry{Word: "кот", Definition: "животное"}, "кот\tживотное"},
+		{domain.DictionaryEntry{Word: "кот", PartOfSpeech: "noun"}, "кот\t\tnoun"},
+		{domain.DictionaryEntry{Word: "кот", FrequencyRank: 12}, "кот\t\t\t12"},
+		{domain.DictionaryEntry{Word: "кот", Tags: []string{"a", "b"}}, "кот\t\t\t\ta,b"},
+		{domain.DictionaryEntry{Word: "белый медведь", Definition: "зверь", PartOfSpeech: "noun", FrequencyRank: 1, Tags: []string{"север"}}, "белый медведь\tзверь\tnoun\t1\tсевер"},
+	}
+	for _, tt := range tests {
+		line := FormatTSV(tt.entry)
+		if line != tt.line {
+			t.Errorf("FormatTSV(%+v) = %q, want %q", tt.entry, line, tt.line)
+		}
+		if got, ok := ParseLine(line); !ok || !reflect.DeepEqual(got, tt.entry) {
+			t.Errorf("ParseLine(%q) = %+v, %v; want %+v back", line, got, ok, tt.entry)
+		}
+	}
+
+	// JSONL entries come back the same through TSV
+	entry, _ := ParseLine(`{"word": "кот", "definition": "животное", "frequencyRank": 5, "tags": ["быт"]}`)
+	if got, _ := ParseLine(FormatTSV(entry)); !reflect.DeepEqual(got, entry) {
+		t.Errorf("JSONL entry %+v came back from TSV as %+v", entry, got)
+	}
+}
@@ -0,0 +1,32 @@
+package domain
+
+import "time"
+
+// AuditEntry records one word list mutation: when it happened, what it did
+// and who asked for it
+type AuditEntry struct {
+	Time      time.Time    `json:"time"`
+	Operation string       `json:"operation"`
+	Language  string       `json:"language"`
+	Client    string       `json:"client,omitempty"`
+	Key       string       `json:"key,omitempty"`
+	RequestID string       `json:"requestId,omitempty"`
+	Reason    string       `json:"reason,omitempty"`
+	Changes   []ListChange `json:"changes"`
+}
+
+// AuditQuery filters the audit log. Zero fields match everything.
+type AuditQuery struct {
+	Language  string
+	Operation string
+	List      string
+	// Word matches entries that added or removed it, on the normalised key
+	Word      string
+	Client    string
+	Key       string
+	RequestID string
+	Since     time.Time
+	Until     time.Time
+	// Limit caps the number of entries returned, newest first
+	Limit int
+}
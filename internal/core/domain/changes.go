@@ -0,0 +1,24 @@
+package domain
+
+import "time"
+
+// OperationBaseline is the first change of a feed: the full lists as they
+// were when the feed started. Followers replace their lists with it.
+const OperationBaseline = "baseline"
+
+// ChangeEvent is one mutation in the changes feed. Versions start at 1 and
+// increase by one per event.
+type ChangeEvent struct {
+	Version   int64        `json:"version"`
+	Time      time.Time    `json:"time"`
+	Operation string       `json:"operation"`
+	Language  string       `json:"language"`
+	Changes   []ListChange `json:"changes"`
+}
+
+// ChangesResponse is a page of the changes feed. Latest is the newest
+// version in the feed, so a reader knows whether to ask again.
+type ChangesResponse struct {
+	Changes []ChangeEvent `json:"changes"`
+	Latest  int64         `json:"latest"`
+}
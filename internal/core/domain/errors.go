@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrInvalidRequest marks errors caused by bad request parameters
+	ErrInvalidRequest = errors.New("invalid request")
+	// ErrNotFound marks requests for things that don't exist
+	ErrNotFound = errors.New("not found")
+	// ErrConflict marks requests that clash with the current state
+	ErrConflict = errors.New("conflict")
+)
+
+// FieldError is an invalid request blamed on one of its fields
+type FieldError struct {
+	// Field is the JSON name or query parameter, such as "lettersMatrix[1][2]"
+	Field   string
+	Message string
+}
+
+// InvalidField returns a FieldError for field; it matches ErrInvalidRequest
+func InvalidField(field, format string, args ...any) error {
+	return &FieldError{Field: field, Message: fmt.Sprintf(format, args...)}
+}
+
+func (e *FieldError) Error() string {
+	return ErrInvalidRequest.Error() + ": " + e.Message
+}
+
+func (e *FieldError) Unwrap() error {
+	return ErrInvalidRequest
+}
+
+// ErrorResponse is the body of every error reply
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+// ErrorBody says what went wrong: Code is stable for clients to branch on,
+// Message is for people
+type ErrorBody struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Field     string `json:"field,omitempty"`
+	RequestID string `json:"requestId,omitempty"`
+}
@@ -0,0 +1,61 @@
+package domain
+
+import "strings"
+
+// SearchFilters restricts which dictionary entries Search considers.
+// Parts of speech and tags are compared case-insensitively. Entries without
+// a part of speech or tags never satisfy an allow list.
+type SearchFilters struct {
+	// AllowPartsOfSpeech keeps only entries whose part of speech is listed
+	AllowPartsOfSpeech []string `json:"allowPartsOfSpeech,omitempty"`
+	// DenyPartsOfSpeech drops entries whose part of speech is listed
+	DenyPartsOfSpeech []string `json:"denyPartsOfSpeech,omitempty"`
+	// AllowTags keeps only entries carrying at least one of the tags
+	AllowTags []string `json:"allowTags,omitempty"`
+	// DenyTags drops entries carrying any of the tags, e.g. slang or profanity
+	DenyTags []string `json:"denyTags,omitempty"`
+}
+
+// IsEmpty reports whether no filter is set
+func (f SearchFilters) IsEmpty() bool {
+	return len(f.AllowPartsOfSpeech) == 0 && len(f.DenyPartsOfSpeech) == 0 &&
+		len(f.AllowTags) == 0 && len(f.DenyTags) == 0
+}
+
+// Matches reports whether entry passes every filter
+func (f SearchFilters) Matches(entry DictionaryEntry) bool {
+	if len(f.AllowPartsOfSpeech) > 0 && !containsFold(f.AllowPartsOfSpeech, entry.PartOfSpeech) {
+		return false
+	}
+	if containsFold(f.DenyPartsOfSpeech, entry.PartOfSpeech) {
+		return false
+	}
+	if len(f.AllowTags) > 0 && !anyContainsFold(f.AllowTags, entry.Tags) {
+		return false
+	}
+	if anyContainsFold(f.DenyTags, entry.Tags) {
+		return false
+	}
+	return true
+}
+
+func containsFold(list []string, value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, item := range list {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func anyContainsFold(list []string, values []string) bool {
+	for _, value := range values {
+		if containsFold(list, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package domain
+
+// Kinds of problem the consistency check reports
+const (
+	// FsckBOM is a byte order mark inside a line
+	FsckBOM = "bom"
+	// FsckEmpty is a blank line
+	FsckEmpty = "empty"
+	// FsckWhitespace is leading, trailing or repeated whitespace in a word
+	FsckWhitespace = "whitespace"
+	// FsckNotNFC is a word not in Unicode NFC, such as a letter spelled as
+	// a base letter and a combining mark, e.g. "е" + U+0308
+	FsckNotNFC = "not_nfc"
+	// FsckNonAlphabet is a word with characters outside the language's
+	// alphabet (other than hyphen and space)
+	FsckNonAlphabet = "non_alphabet"
+	// FsckUnparseable is a JSON line that does not parse
+	FsckUnparseable = "unparseable"
+	// FsckDuplicate is a word listed twice in the same list
+	FsckDuplicate = "duplicate"
+	// FsckYoTwin is a word listed with both "ё" and "е" spellings
+	FsckYoTwin = "yo_twin"
+	// FsckInDictionary is a merged word the dictionary file already has
+	FsckInDictionary = "in_dictionary"
+	// FsckIncludeExclude is a word in both the include and exclude lists
+	FsckIncludeExclude = "include_and_exclude"
+	// FsckCorrupt is a file that fails its integrity checks, such as one that
+	// no longer matches its checksum; its lines are not checked
+	FsckCorrupt = "corrupt"
+)
+
+// Lists the check covers; "definitions" is the pack's dictionary file
+const (
+	FsckListDefinitions = "definitions"
+	FsckListMerged      = "merged"
+	FsckListInclude     = "include"
+	FsckListExclude     = "exclude"
+)
+
+// FsckRequest runs the consistency check on one language
+type FsckRequest struct {
+	Language string `json:"language,omitempty"`
+	// Fix rewrites the lists without the fixable problems
+	Fix bool `json:"fix,omitempty"`
+	// Limit caps the issues listed in the report; counts are always complete
+	Limit int `json:"limit,omitempty"`
+}
+
+// FsckIssue is one problem found on one line
+type FsckIssue struct {
+	List    string `json:"list"`
+	Line    int    `json:"line"`
+	Kind    string `json:"kind"`
+	Word    string `json:"word"`
+	Detail  string `json:"detail,omitempty"`
+	Fixable bool   `json:"fixable"`
+}
+
+// FsckReport lists what the check found and, in fix mode, what it changed
+type FsckReport struct {
+	Language  string         `json:"language"`
+	Issues    []FsckIssue    `json:"issues"`
+	Counts    map[string]int `json:"counts"`
+	Truncated bool           `json:"truncated,omitempty"`
+	Fixed     bool           `json:"fixed"`
+	Changes   []ListChange   `json:"changes,omitempty"`
+}
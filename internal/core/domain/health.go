@@ -0,0 +1,15 @@
+package domain
+
+// HealthCheck is the outcome of one readiness check
+type HealthCheck struct {
+	Name  string `json:"name"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// HealthResponse is returned by /healthz and /readyz; Status is "ok",
+// "unavailable" or "draining"
+type HealthResponse struct {
+	Status string        `json:"status"`
+	Checks []HealthCheck `json:"checks,omitempty"`
+}
@@ -30,31 +30,157 @@ func (fi *FlexInt) UnmarshalJSON(b []byte) error {
 	return nil
 }
 
+// MaskedCell marks a board cell no letter is on
+const MaskedCell = "#"
+
 // SearchRequest represents the request payload for the search endpoint
 type SearchRequest struct {
-	MaxLength     FlexInt    `json:"maxLength"`
-	MinLength     FlexInt    `json:"minLength"`
-	MaxWords      FlexInt    `json:"maxWords"`
+	MaxLength FlexInt `json:"maxLength"`
+	MinLength FlexInt `json:"minLength"`
+	// MaxWords caps the words returned; 0, the same as leaving it out, uses
+	// the server default
+	MaxWords FlexInt `json:"maxWords"`
+	// LettersMatrix is the board by row. Rows may differ in length, and
+	// MaskedCell marks a cell that isn't part of the board.
 	LettersMatrix [][]string `json:"lettersMatrix"`
+	Language      string     `json:"language,omitempty"`
+	// IncludeDetails returns definitions and metadata with each found word
+	IncludeDetails bool `json:"includeDetails,omitempty"`
+	// Filters are applied to dictionary entries before path finding
+	Filters SearchFilters `json:"filters,omitempty"`
+	// SortBy is "length" (default, longest first) or "frequency" (most common first)
+	SortBy string `json:"sortBy,omitempty"`
+	// MaxRarity drops words rarer than the given tier: "common", "medium" or "rare"
+	MaxRarity string `json:"maxRarity,omitempty"`
+}
+
+// DictionaryEntry is a dictionary word with its optional definition and metadata
+type DictionaryEntry struct {
+	Word          string   `json:"word"`
+	Definition    string   `json:"definition,omitempty"`
+	PartOfSpeech  string   `json:"partOfSpeech,omitempty"`
+	FrequencyRank int      `json:"frequencyRank,omitempty"`
+	Tags          []string `json:"tags,omitempty"`
+}
+
+// FoundWord is a word found on the board together with its path
+type FoundWord struct {
+	DictionaryEntry
+	Tier Tier                      `json:"tier"`
+	Path map[int]map[string]string `json:"path"`
+	// Score sums the language pack's letter scores
+	Score int `json:"score"`
+}
+
+// SearchResponse is the detailed search response, ordered by rank
+type SearchResponse struct {
+	Words []FoundWord `json:"words"`
 }
 
 // UpdateWordsRequest represents the request payload for updating words
 type UpdateWordsRequest struct {
-	Words   []string `json:"words"`
-	Include bool     `json:"include"`
+	Words    []string `json:"words"`
+	Include  bool     `json:"include"`
+	Language string   `json:"language,omitempty"`
+	// Submitter identifies who asked. The handler sets it to the API key name
+	// or client address; it is never read from the body.
+	Submitter string `json:"-"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// RemoveWordsRequest represents the request payload for removing words from
+// the include or exclude list
+type RemoveWordsRequest struct {
+	Words    []string `json:"words"`
+	Include  bool     `json:"include"`
+	Language string   `json:"language,omitempty"`
+}
+
+// RemoveMergedRequest represents the request payload for removing words from merged.txt
+type RemoveMergedRequest struct {
+	Words    []string `json:"words"`
+	Language string   `json:"language,omitempty"`
+}
+
+// RemoveWordsResponse reports the entries removed, as they were stored, and
+// the requested words that matched nothing
+type RemoveWordsResponse struct {
+	Removed      []string `json:"removed"`
+	RemovedCount int      `json:"removedCount"`
+	NotFound     []string `json:"notFound"`
+}
+
+// Reasons a word is not offered for merging
+const (
+	MergeReasonEmpty          = "empty"
+	MergeReasonTooShort       = "too_short"
+	MergeReasonHyphen         = "hyphen"
+	MergeReasonSpace          = "space"
+	MergeReasonAlreadyPresent = "already_present"
+	MergeReasonDuplicate      = "duplicate"
+)
+
+// MergeRejection explains why a word was not offered for merging
+type MergeRejection struct {
+	Word   string `json:"word"`
+	Reason string `json:"reason"`
+}
+
+// MergePreviewResponse lists the merge candidates and the rejected includes
+type MergePreviewResponse struct {
+	Candidates []string         `json:"candidates"`
+	Rejected   []MergeRejection `json:"rejected"`
+}
+
+// MergeCommitRequest optionally narrows a merge to some of the previewed candidates
+type MergeCommitRequest struct {
+	Words    []string `json:"words,omitempty"`
+	Language string   `json:"language,omitempty"`
 }
 
-// MergeResponse represents the response for the merge operation
+// MergeResponse represents the response for the merge operation.
+// AddedCount counts words appended to merged.txt, RemovedCount the entries
+// that left include.txt as a result.
 type MergeResponse struct {
-	AddedCount   int `json:"addedCount"`
-	RemovedCount int `json:"removedCount"`
+	AddedCount   int              `json:"addedCount"`
+	RemovedCount int              `json:"removedCount"`
+	Added        []string         `json:"added"`
+	Rejected     []MergeRejection `json:"rejected"`
+}
+
+// WordResponse is every place a word was found, for GET /v1/words/{word}
+type WordResponse struct {
+	Word    string                     `json:"word"`
+	Results []LookupResultResponseItem `json:"results"`
+}
+
+// ListWordResponse is a word's entry in the include or exclude list
+type ListWordResponse struct {
+	List     string `json:"list"`
+	Language string `json:"language"`
+	Word     string `json:"word"`
+}
+
+// ListWordRequest is the optional body when adding a word to a list
+type ListWordRequest struct {
+	Language string `json:"language,omitempty"`
+	Reason   string `json:"reason,omitempty"`
 }
 
 // LookupResultResponseItem represents an item in the lookup result
 type LookupResultResponseItem struct {
-	Word      string `json:"word"`
-	Found     bool   `json:"found"`
-	Source    string `json:"source"`
-	Line      int    `json:"line"`
+	Word   string `json:"word"`
+	Found  bool   `json:"found"`
+	Source string `json:"source"`
+	Line   int    `json:"line"`
+	// Timestamp and Operation come from the audit entry that put the word
+	// in its list; words that predate the audit log have neither
 	Timestamp string `json:"timestamp"`
+	Operation string `json:"operation,omitempty"`
+
+	// Set when details are requested and the source carries them
+	Definition    string   `json:"definition,omitempty"`
+	PartOfSpeech  string   `json:"partOfSpeech,omitempty"`
+	FrequencyRank int      `json:"frequencyRank,omitempty"`
+	Tags          []string `json:"tags,omitempty"`
 }
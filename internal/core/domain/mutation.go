@@ -0,0 +1,38 @@
+package domain
+
+// Operations recorded for word list mutations
+const (
+	OperationAdd      = "add"
+	OperationRemove   = "remove"
+	OperationMerge    = "merge"
+	OperationRollback = "rollback"
+	// OperationReplicate applies a change from the leader's feed
+	OperationReplicate = "replicate"
+	// OperationRepair is the consistency check fixing the lists
+	OperationRepair = "repair"
+)
+
+// ListChange is what one operation did to one word list
+type ListChange struct {
+	List    string   `json:"list"`
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// Mutation describes one completed change to the word lists of a language
+type Mutation struct {
+	Operation string       `json:"operation"`
+	Language  string       `json:"language"`
+	Reason    string       `json:"reason,omitempty"`
+	Changes   []ListChange `json:"changes"`
+}
+
+// IsEmpty reports whether the mutation changed nothing
+func (m Mutation) IsEmpty() bool {
+	for _, c := range m.Changes {
+		if len(c.Added) > 0 || len(c.Removed) > 0 {
+			return false
+		}
+	}
+	return true
+}
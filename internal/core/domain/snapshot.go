@@ -0,0 +1,62 @@
+package domain
+
+import "time"
+
+// Snapshot lists stored for each language. The composed dictionary is the
+// base dictionary plus merged.
+const (
+	SnapshotDefinitions = "definitions"
+	SnapshotMerged      = "merged"
+	SnapshotInclude     = "include"
+	SnapshotExclude     = "exclude"
+	// SnapshotDictionary is the composed dictionary, only used in diffs
+	SnapshotDictionary = "dictionary"
+)
+
+// Snapshot is a numbered copy of one language's dictionary and word lists
+type Snapshot struct {
+	ID        int                     `json:"id"`
+	Language  string                  `json:"language"`
+	CreatedAt time.Time               `json:"createdAt"`
+	Trigger   string                  `json:"trigger"`
+	Reason    string                  `json:"reason,omitempty"`
+	Lists     map[string]SnapshotList `json:"lists"`
+}
+
+// SnapshotList points at the stored content of one list in a snapshot
+type SnapshotList struct {
+	Blob  string `json:"blob"`
+	Count int    `json:"count"`
+}
+
+// CreateSnapshotRequest takes a snapshot on demand
+type CreateSnapshotRequest struct {
+	Language string `json:"language,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// RollbackRequest restores the word lists of a snapshot
+type RollbackRequest struct {
+	ID int `json:"id"`
+}
+
+// ListDiff is the difference between two versions of a list
+type ListDiff struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
+
+// SnapshotDiff compares two snapshots of the same language, list by list.
+// From or To is 0 for the current, unsnapshotted state.
+type SnapshotDiff struct {
+	From  int                 `json:"from"`
+	To    int                 `json:"to"`
+	Lists map[string]ListDiff `json:"lists"`
+}
+
+// RollbackResponse reports what a rollback changed and the snapshot taken after it
+type RollbackResponse struct {
+	RestoredFrom int          `json:"restoredFrom"`
+	Diff         SnapshotDiff `json:"diff"`
+	Snapshot     Snapshot     `json:"snapshot"`
+}
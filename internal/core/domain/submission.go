@@ -0,0 +1,46 @@
+package domain
+
+import "time"
+
+// SubmissionStatus is the moderation state of a submission
+type SubmissionStatus string
+
+const (
+	SubmissionPending  SubmissionStatus = "pending"
+	SubmissionApproved SubmissionStatus = "approved"
+	SubmissionRejected SubmissionStatus = "rejected"
+)
+
+// Submission is a player's request to add a word to the include or exclude
+// list, waiting for a moderator. Repeated submissions of the same pending
+// word count as votes, one per submitter.
+type Submission struct {
+	ID          string           `json:"id"`
+	Word        string           `json:"word"`
+	Language    string           `json:"language"`
+	Include     bool             `json:"include"`
+	Status      SubmissionStatus `json:"status"`
+	Submitter   string           `json:"submitter"`
+	Reason      string           `json:"reason,omitempty"`
+	SubmittedAt time.Time        `json:"submittedAt"`
+	Votes       int              `json:"votes"`
+	Voters      []string         `json:"voters"`
+
+	DecidedAt      *time.Time `json:"decidedAt,omitempty"`
+	DecidedBy      string     `json:"decidedBy,omitempty"`
+	DecisionReason string     `json:"decisionReason,omitempty"`
+	AutoApproved   bool       `json:"autoApproved,omitempty"`
+}
+
+// SubmissionDecisionRequest approves or rejects a submission
+type SubmissionDecisionRequest struct {
+	ID     string `json:"id"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// SubmitWordsResponse reports how the submitted words were queued
+type SubmitWordsResponse struct {
+	Submissions []Submission `json:"submissions"`
+	// Skipped lists words already in the target list or empty after normalisation
+	Skipped []string `json:"skipped"`
+}
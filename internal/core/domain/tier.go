@@ -0,0 +1,42 @@
+package domain
+
+import "strings"
+
+// Tier is a difficulty tier derived from a word's frequency rank
+type Tier string
+
+const (
+	TierCommon Tier = "common"
+	TierMedium Tier = "medium"
+	TierRare   Tier = "rare"
+)
+
+// ParseTier parses a tier name; an empty name means no cutoff and parses as rare
+func ParseTier(s string) (Tier, error) {
+	switch Tier(strings.ToLower(s)) {
+	case TierCommon:
+		return TierCommon, nil
+	case TierMedium:
+		return TierMedium, nil
+	case TierRare, "":
+		return TierRare, nil
+	}
+	return "", InvalidField("maxRarity", "unknown rarity %q", s)
+}
+
+// Level orders tiers from common (0) to rare (2)
+func (t Tier) Level() int {
+	switch t {
+	case TierCommon:
+		return 0
+	case TierMedium:
+		return 1
+	}
+	return 2
+}
+
+// Search sort orders
+const (
+	SortByLength    = "length"
+	SortByFrequency = "frequency"
+)
@@ -0,0 +1,67 @@
+package language
+
+import "service-matrix-go/internal/core/normalization"
+
+// Default tier boundaries, in frequency rank
+const (
+	defaultCommonRankLimit = 10000
+	defaultMediumRankLimit = 50000
+)
+
+// Russian is the default pack and uses the original resources/ and data/ layout
+func Russian() *Pack {
+	return &Pack{
+		Name:            "ru",
+		Alphabet:        "абвгдеёжзийклмнопрстуфхцчшщъыьэюя",
+		ResourceDir:     "resources",
+		DataDir:         "data",
+		DictionaryFile:  "definitions.txt",
+		FrequencyFile:   "frequencies.txt",
+		CommonRankLimit: defaultCommonRankLimit,
+		MediumRankLimit: defaultMediumRankLimit,
+		LetterFrequencies: map[string]float64{
+			"о": 10.97, "е": 8.45, "а": 8.01, "и": 7.35, "н": 6.70, "т": 6.26,
+			"с": 5.47, "р": 4.73, "в": 4.54, "л": 4.40, "к": 3.49, "м": 3.21,
+			"д": 2.98, "п": 2.81, "у": 2.62, "я": 2.01, "ы": 1.90, "ь": 1.74,
+			"г": 1.70, "з": 1.65, "б": 1.59, "ч": 1.44, "й": 1.21, "х": 0.97,
+			"ж": 0.94, "ш": 0.73, "ю": 0.64, "ц": 0.48, "щ": 0.36, "э": 0.32,
+			"ф": 0.26, "ъ": 0.04, "ё": 0.04,
+		},
+		LetterScores: map[string]int{
+			"а": 1, "б": 3, "в": 1, "г": 3, "д": 2, "е": 1, "ё": 3, "ж": 5,
+			"з": 5, "и": 1, "й": 4, "к": 2, "л": 2, "м": 2, "н": 1, "о": 1,
+			"п": 2, "р": 1, "с": 1, "т": 1, "у": 2, "ф": 10, "х": 5, "ц": 5,
+			"ч": 5, "ш": 8, "щ": 10, "ъ": 10, "ы": 4, "ь": 3, "э": 8, "ю": 8,
+			"я": 3,
+		},
+		Normalization: normalization.DefaultOptions,
+	}
+}
+
+// English keeps its files under resources/en and data/en
+func English() *Pack {
+	return &Pack{
+		Name:            "en",
+		Alphabet:        "abcdefghijklmnopqrstuvwxyz",
+		ResourceDir:     "resources/en",
+		DataDir:         "data/en",
+		DictionaryFile:  "definitions.txt",
+		FrequencyFile:   "frequencies.txt",
+		CommonRankLimit: defaultCommonRankLimit,
+		MediumRankLimit: defaultMediumRankLimit,
+		LetterFrequencies: map[string]float64{
+			"e": 12.70, "t": 9.06, "a": 8.17, "o": 7.51, "i": 6.97, "n": 6.75,
+			"s": 6.33, "h": 6.09, "r": 5.99, "d": 4.25, "l": 4.03, "c": 2.78,
+			"u": 2.76, "m": 2.41, "w": 2.36, "f": 2.23, "g": 2.02, "y": 1.97,
+			"p": 1.93, "b": 1.29, "v": 0.98, "k": 0.77, "j": 0.15, "x": 0.15,
+			"q": 0.10, "z": 0.07,
+		},
+		LetterScores: map[string]int{
+			"a": 1, "b": 3, "c": 3, "d": 2, "e": 1, "f": 4, "g": 2, "h": 4,
+			"i": 1, "j": 8, "k": 5, "l": 1, "m": 3, "n": 1, "o": 1, "p": 3,
+			"q": 10, "r": 1, "s": 1, "t": 1, "u": 1, "v": 4, "w": 4, "x": 8,
+			"y": 4, "z": 10,
+		},
+		Normalization: normalization.Options{FoldYo: false},
+	}
+}
@@ -0,0 +1,120 @@
+package language
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"service-matrix-go/internal/core/domain"
+	"service-matrix-go/internal/core/normalization"
+)
+
+// Pack bundles everything that differs between languages: where the
+// dictionary and word lists live, the alphabet, letter statistics and
+// normalisation rules
+type Pack struct {
+	Name string `json:"name"`
+
+	// Alphabet lists every letter that may appear on a board
+	Alphabet string `json:"alphabet"`
+
+	// ResourceDir holds the dictionary and merged.txt, DataDir the
+	// include/exclude lists. Both are relative to the service base dir.
+	ResourceDir    string `json:"resourceDir"`
+	DataDir        string `json:"dataDir"`
+	DictionaryFile string `json:"dictionaryFile"`
+	// FrequencyFile lists words most common first, next to the dictionary
+	FrequencyFile string `json:"frequencyFile"`
+
+	// CommonRankLimit and MediumRankLimit split frequency ranks into tiers:
+	// ranks up to CommonRankLimit are common, up to MediumRankLimit medium,
+	// everything else (including unranked words) rare
+	CommonRankLimit int `json:"commonRankLimit"`
+	MediumRankLimit int `json:"mediumRankLimit"`
+
+	// LetterFrequencies is the share of each letter in running text, in percent
+	LetterFrequencies map[string]float64 `json:"letterFrequencies"`
+	// LetterScores is the tile value of each letter
+	LetterScores map[string]int `json:"letterScores"`
+
+	Normalization normalization.Options `json:"normalization"`
+
+	once       sync.Once
+	normalizer *normalization.Normalizer
+	letters    map[rune]bool
+}
+
+// Normalizer returns the normalizer configured by the pack's rules
+func (p *Pack) Normalizer() *normalization.Normalizer {
+	p.init()
+	return p.normalizer
+}
+
+// InAlphabet reports whether r is a letter of the pack's alphabet.
+// Comparison is done on the normalised form, so upper case letters count.
+func (p *Pack) InAlphabet(r rune) bool {
+	p.init()
+	return p.letters[r]
+}
+
+// TierFor returns the difficulty tier of a word with the given frequency rank
+func (p *Pack) TierFor(rank int) domain.Tier {
+	switch {
+	case rank <= 0:
+		return domain.TierRare
+	case rank <= p.CommonRankLimit:
+		return domain.TierCommon
+	case rank <= p.MediumRankLimit:
+		return domain.TierMedium
+	}
+	return domain.TierRare
+}
+
+// Score sums the letter values of word. Letters are scored in their
+// normalised form, so with FoldYo set ё scores as е.
+func (p *Pack) Score(word string) int {
+	score := 0
+	for _, r := range p.Normalizer().Key(word) {
+		score += p.LetterScores[string(r)]
+	}
+	return score
+}
+
+// checkLetters reports the first key of the letter tables that isn't a
+// single letter of the alphabet
+func (p *Pack) checkLetters() error {
+	tables := map[string][]string{}
+	for letter := range p.LetterFrequencies {
+		tables["letterFrequencies"] = append(tables["letterFrequencies"], letter)
+	}
+	for letter := range p.LetterScores {
+		tables["letterScores"] = append(tables["letterScores"], letter)
+	}
+	for _, table := range []string{"letterFrequencies", "letterScores"} {
+		letters := tables[table]
+		sort.Strings(letters)
+		for _, letter := range letters {
+			r, size := utf8.DecodeRuneInString(letter)
+			if size == 0 || size != len(letter) || !p.InAlphabet(r) {
+				return fmt.Errorf("%s: %q is not a letter of the alphabet", table, letter)
+			}
+		}
+	}
+	return nil
+}
+
+func (p *Pack) init() {
+	p.once.Do(func() {
+		p.normalizer = normalization.NewNormalizer(p.Normalization)
+		p.letters = make(map[rune]bool)
+		for _, r := range p.normalizer.Key(p.Alphabet) {
+			p.letters[r] = true
+		}
+		// ё folds into е, so a folded key never contains ё
+		if p.Normalization.FoldYo && strings.ContainsRune(p.Alphabet, 'ё') {
+			p.letters['ё'] = true
+		}
+	})
+}
@@ -0,0 +1,25 @@
+package language
+
+import (
+	"testing"
+
+	"service-matrix-go/internal/core/domain"
+)
+
+func TestTierFor(t *testing.T) {
+	p := &Pack{CommonRankLimit: 10, MediumRankLimit: 100}
+	tests := map[int]domain.Tier{
+		-1:  domain.TierRare,
+		0:   domain.TierRare, // unranked
+		1:   domain.TierCommon,
+		10:  domain.TierCommon,
+		11:  domain.TierMedium,
+		100: domain.TierMedium,
+		101: domain.TierRare,
+	}
+	for rank, want := range tests {
+		if got := p.TierFor(rank); got != want {
+			t.Errorf("TierFor(%d) = %s, want %s", rank, got, want)
+		}
+	}
+}
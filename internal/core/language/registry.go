@@ -0,0 +1,138 @@
+package language
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ErrUnknownLanguage is returned when a request names a pack that isn't registered
+var ErrUnknownLanguage = errors.New("unknown language")
+
+// Registry holds the available language packs
+type Registry struct {
+	packs       map[string]*Pack
+	defaultName string
+	layout      Layout
+}
+
+// Layout says where packs keep their files when they don't say otherwise
+type Layout struct {
+	ResourceDir    string
+	DataDir        string
+	DictionaryFile string
+	FrequencyFile  string
+}
+
+// DefaultLayout is the original resources/ and data/ layout
+var DefaultLayout = Layout{
+	ResourceDir:    "resources",
+	DataDir:        "data",
+	DictionaryFile: "definitions.txt",
+	FrequencyFile:  "frequencies.txt",
+}
+
+// NewRegistry returns a registry with the built-in packs, defaulting to Russian
+func NewRegistry() *Registry {
+	return NewRegistryWithLayout(DefaultLayout)
+}
+
+// NewRegistryWithLayout is NewRegistry with the built-in packs moved into
+// layout's directories and file names
+func NewRegistryWithLayout(layout Layout) *Registry {
+	r := &Registry{packs: make(map[string]*Pack), defaultName: "ru", layout: layout}
+	for _, p := range []*Pack{Russian(), English()} {
+		p.ResourceDir = rebase(p.ResourceDir, DefaultLayout.ResourceDir, layout.ResourceDir)
+		p.DataDir = rebase(p.DataDir, DefaultLayout.DataDir, layout.DataDir)
+		p.DictionaryFile = layout.DictionaryFile
+		p.FrequencyFile = layout.FrequencyFile
+		r.Register(p)
+	}
+	return r
+}
+
+// rebase moves dir from under the from directory to under to
+func rebase(dir, from, to string) string {
+	rel, err := filepath.Rel(from, dir)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return dir
+	}
+	return filepath.Join(to, rel)
+}
+
+// Register adds p, replacing any pack with the same name
+func (r *Registry) Register(p *Pack) {
+	r.packs[p.Name] = p
+}
+
+// Get returns the named pack, or the default pack when name is empty
+func (r *Registry) Get(name string) (*Pack, error) {
+	if name == "" {
+		name = r.defaultName
+	}
+	p, ok := r.packs[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownLanguage, name)
+	}
+	return p, nil
+}
+
+// Names returns the registered pack names in sorted order
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.packs))
+	for name := range r.packs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadDir registers every <name>.json pack found in dir. Packs loaded this
+// way override built-in packs of the same name. A missing dir is not an error.
+func (r *Registry) LoadDir(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		var p Pack
+		if err := json.Unmarshal(data, &p); err != nil {
+			return fmt.Errorf("language pack %s: %w", file, err)
+		}
+		if p.Name == "" {
+			p.Name = strings.TrimSuffix(filepath.Base(file), ".json")
+		}
+		p.Name = strings.ToLower(p.Name)
+		if p.ResourceDir == "" {
+			p.ResourceDir = filepath.Join(r.layout.ResourceDir, p.Name)
+		}
+		if p.DataDir == "" {
+			p.DataDir = filepath.Join(r.layout.DataDir, p.Name)
+		}
+		if p.DictionaryFile == "" {
+			p.DictionaryFile = r.layout.DictionaryFile
+		}
+		if p.FrequencyFile == "" {
+			p.FrequencyFile = r.layout.FrequencyFile
+		}
+		if p.CommonRankLimit == 0 {
+			p.CommonRankLimit = defaultCommonRankLimit
+		}
+		if p.MediumRankLimit == 0 {
+			p.MediumRankLimit = defaultMediumRankLimit
+		}
+		if err := p.checkLetters(); err != nil {
+			return fmt.Errorf("language pack %s: %w", file, err)
+		}
+		r.Register(&p)
+	}
+	return nil
+}
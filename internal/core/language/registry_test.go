@@ -0,0 +1,136 @@
+package language
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBuiltInPacks(t *testing.T) {
+	r := NewRegistry()
+	if names := r.Names(); !slices.Equal(names, []string{"en", "ru"}) {
+		t.Errorf("built-in packs %q, want en and ru", names)
+	}
+	if p, err := r.Get(""); err != nil || p.Name != "ru" {
+		t.Errorf("default pack %v, %v; want ru", p, err)
+	}
+	if _, err := r.Get("uk"); !errors.Is(err, ErrUnknownLanguage) {
+		t.Errorf("Get(uk) without a pack file: %v, want ErrUnknownLanguage", err)
+	}
+
+	for _, name := range r.Names() {
+		p, _ := r.Get(name)
+		if err := p.checkLetters(); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+		// Every letter has a score and a frequency
+		for _, letter := range p.Alphabet {
+			if p.LetterScores[string(letter)] == 0 || p.LetterFrequencies[string(letter)] == 0 {
+				t.Errorf("%s: %c has no score or frequency", name, letter)
+			}
+		}
+	}
+}
+
+func TestBuiltInDictionaries(t *testing.T) {
+	r := NewRegistry()
+	for _, name := range r.Names() {
+		p, _ := r.Get(name)
+		data, err := os.ReadFile(filepath.Join("..", "..", "..", p.ResourceDir, p.DictionaryFile))
+		if err != nil {
+			t.Errorf("%s has no dictionary: %v", name, err)
+			continue
+		}
+		if lines := strings.Count(string(data), "\n"); lines < 1000 {
+			t.Errorf("%s dictionary has %d words", name, lines)
+		}
+	}
+}
+
+func TestScore(t *testing.T) {
+	r := NewRegistry()
+	ru, _ := r.Get("ru")
+	en, _ := r.Get("en")
+	tests := []struct {
+		pack *Pack
+		word string
+		want int
+	}{
+		{ru, "кот", 2 + 1 + 1},
+		{ru, "Щука", 10 + 2 + 2 + 1},
+		// ё folds into е, and scores as е
+		{ru, "ёж", 1 + 5},
+		{en, "Quiz", 10 + 1 + 1 + 10},
+		// Letters outside the alphabet score nothing
+		{en, "cat's", 3 + 1 + 1 + 1},
+		{en, "", 0},
+	}
+	for _, tt := range tests {
+		if got := tt.pack.Score(tt.word); got != tt.want {
+			t.Errorf("%s Score(%q) = %d, want %d", tt.pack.Name, tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	pack := `{"name": "EN", "alphabet": "abcdefghijklmnopqrstuvwxyz", "letterFrequencies": {"e": 12.7, "z": 0.07}, "letterScores": {"a": 1, "z": 10}, "normalization": {"foldYo": false}}`
+	if err := os.WriteFile(filepath.Join(dir, "en.json"), []byte(pack), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewRegistryWithLayout(Layout{ResourceDir: "res", DataDir: "lists", DictionaryFile: "words.txt", FrequencyFile: "freq.txt"})
+	if err := r.LoadDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	en, err := r.Get("en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := []string{en.Name, en.ResourceDir, en.DataDir, en.DictionaryFile, en.FrequencyFile}
+	want := []string{"en", filepath.Join("res", "en"), filepath.Join("lists", "en"), "words.txt", "freq.txt"}
+	if !slices.Equal(got, want) {
+		t.Errorf("loaded pack name and files %q, want %q", got, want)
+	}
+	if en.CommonRankLimit != defaultCommonRankLimit || en.MediumRankLimit != defaultMediumRankLimit || en.Normalization.FoldYo {
+		t.Errorf("loaded pack %+v, want the default tiers without ё folding", en)
+	}
+	if !en.InAlphabet('q') || en.InAlphabet('я') {
+		t.Error("en alphabet is wrong")
+	}
+	// The file's tables replace the built-in ones
+	if len(en.LetterScores) != 2 || en.LetterFrequencies["e"] != 12.7 || en.Score("jazz") != 21 {
+		t.Errorf("loaded scores %v and frequencies %v, want the file's", en.LetterScores, en.LetterFrequencies)
+	}
+
+	ru, _ := r.Get("ru")
+	if ru.ResourceDir != "res" || ru.DataDir != "lists" || ru.DictionaryFile != "words.txt" {
+		t.Errorf("ru pack reads %s/%s with lists in %s, want the layout's", ru.ResourceDir, ru.DictionaryFile, ru.DataDir)
+	}
+}
+
+func TestLoadDirRejectsBadLetterTables(t *testing.T) {
+	tests := map[string]string{
+		"letterScores":      `{"alphabet": "abc", "letterScores": {"d": 2}}`,
+		"letterFrequencies": `{"alphabet": "abc", "letterFrequencies": {"ab": 2}}`,
+		"upper case":        `{"alphabet": "abc", "letterScores": {"A": 1}}`,
+	}
+	for name, pack := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "xx.json"), []byte(pack), 0644); err != nil {
+				t.Fatal(err)
+			}
+			r := NewRegistry()
+			if err := r.LoadDir(dir); err == nil || !strings.Contains(err.Error(), "not a letter") {
+				t.Errorf("LoadDir: %v, want the bad letter reported", err)
+			}
+			if _, err := r.Get("xx"); err == nil {
+				t.Error("the bad pack was registered")
+			}
+		})
+	}
+}
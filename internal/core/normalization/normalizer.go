@@ -0,0 +1,64 @@
+package normalization
+
+import (
+	"strings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// bom is the UTF-8 byte order mark some editors put at the start of files
+const bom = "\uFEFF"
+
+// Options controls the foldings applied when building comparison keys
+type Options struct {
+	// FoldYo folds "ё" into "е" so both spellings compare equal
+	FoldYo bool `json:"foldYo"`
+}
+
+// DefaultOptions matches the folding MergeWords has always applied
+var DefaultOptions = Options{FoldYo: true}
+
+// Normalizer turns raw words and board cells into display and comparison forms
+type Normalizer struct {
+	opts Options
+}
+
+func NewNormalizer(opts Options) *Normalizer {
+	return &Normalizer{opts: opts}
+}
+
+// Options returns the options the normalizer was created with
+func (n *Normalizer) Options() Options {
+	return n.opts
+}
+
+// Clean returns the display form of s: BOM stripped, surrounding whitespace
+// trimmed and in NFC. Case and ё are preserved.
+func (n *Normalizer) Clean(s string) string {
+	return NFC(strings.TrimSpace(StripBOM(s)))
+}
+
+// Key returns the comparison form of s: the cleaned word, case folded and,
+// if enabled, with ё folded into е
+func (n *Normalizer) Key(s string) string {
+	// A Caser keeps state, so each call gets its own. Folding can undo
+	// composition, hence NFC again.
+	key := NFC(cases.Fold().String(n.Clean(s)))
+	if n.opts.FoldYo {
+		key = strings.ReplaceAll(key, "ё", "е")
+	}
+	return key
+}
+
+// StripBOM removes a leading byte order mark
+func StripBOM(s string) string {
+	return strings.TrimPrefix(s, bom)
+}
+
+// NFC returns s in Unicode normalization form C, so a letter typed as a
+// base letter and a combining mark, e.g. "е" + U+0308, becomes the
+// precomposed letter
+func NFC(s string) string {
+	return norm.NFC.String(s)
+}
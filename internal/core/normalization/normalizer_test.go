@@ -0,0 +1,78 @@
+package normalization
+
+import "testing"
+
+const (
+	// Decomposed spellings: a base letter and a combining mark
+	decomposedYo = "е\u0308"
+	decomposedYi = "и\u0306"
+)
+
+func TestClean(t *testing.T) {
+	n := NewNormalizer(DefaultOptions)
+	tests := map[string]string{
+		"кот":                       "кот",
+		"\uFEFFкот":                 "кот",
+		"  Кот\t\n":                 "Кот",
+		"\uFEFF  ёж ":               "ёж",
+		decomposedYo + "ж":          "ёж",
+		"сара" + decomposedYi:       "сарай",
+		"Е\u0308лка":                "Ёлка",
+		"caf" + "e\u0301":           "café",
+		"к\u0308от":                 "к\u0308от", // no precomposed к with a diaeresis
+		"к\u0301от":                 "\u045Cот",  // ќ is precomposed
+		"кот\uFEFF":                 "кот\uFEFF", // only a leading BOM is a BOM
+		"":                          "",
+		"\uFEFF":                    "",
+		decomposedYo + decomposedYo: "ёё",
+	}
+	for in, want := range tests {
+		if got := n.Clean(in); got != want {
+			t.Errorf("Clean(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		in     string
+		foldYo bool
+		want   string
+	}{
+		{"Кот", true, "кот"},
+		{"ёж", true, "еж"},
+		{"ЁЖ", true, "еж"},
+		{decomposedYo + "ж", true, "еж"},
+		{"ёж", false, "ёж"},
+		{"ЁЖ", false, "ёж"},
+		{decomposedYo + "ж", false, "ёж"},
+		{"Сара" + decomposedYi, true, "сарай"},
+		{"Сара" + decomposedYi, false, "сарай"},
+		{"\uFEFF Ёлка ", true, "елка"},
+		// Full case folding, not just lower-casing
+		{"Straße", true, "strasse"},
+		{"ΟΔΟΣ", true, "οδοσ"},
+		{"οδος", true, "οδοσ"},
+	}
+	for _, tt := range tests {
+		n := NewNormalizer(Options{FoldYo: tt.foldYo})
+		if got := n.Key(tt.in); got != tt.want {
+			t.Errorf("Key(%q) with foldYo %v = %q, want %q", tt.in, tt.foldYo, got, tt.want)
+		}
+	}
+}
+
+func TestComposedAndDecomposedShareAKey(t *testing.T) {
+	n := NewNormalizer(DefaultOptions)
+	pairs := [][2]string{
+		{"ёлка", decomposedYo + "лка"},
+		{"елка", decomposedYo + "лка"},
+		{"йод", decomposedYi + "од"},
+		{"ЙОД", decomposedYi + "од"},
+	}
+	for _, p := range pairs {
+		if a, b := n.Key(p[0]), n.Key(p[1]); a != b {
+			t.Errorf("Key(%q) = %q but Key(%q) = %q", p[0], a, p[1], b)
+		}
+	}
+}
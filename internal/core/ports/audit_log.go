@@ -0,0 +1,15 @@
+package ports
+
+import (
+	"context"
+
+	"service-matrix-go/internal/core/domain"
+)
+
+// AuditLog is an append-only record of word list mutations
+type AuditLog interface {
+	// Append records an entry
+	Append(ctx context.Context, entry domain.AuditEntry) error
+	// Entries returns every entry, oldest first
+	Entries(ctx context.Context) ([]domain.AuditEntry, error)
+}
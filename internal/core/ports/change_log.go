@@ -0,0 +1,21 @@
+package ports
+
+import (
+	"context"
+
+	"service-matrix-go/internal/core/domain"
+)
+
+// ChangeLog stores the versioned changes feed
+type ChangeLog interface {
+	// Append assigns the event the next version, stores it and returns it
+	Append(ctx context.Context, event domain.ChangeEvent) (domain.ChangeEvent, error)
+	// Since returns up to limit events newer than version, oldest first,
+	// and the latest version
+	Since(ctx context.Context, version int64, limit int) (domain.ChangesResponse, error)
+}
+
+// ChangeSource reads another instance's changes feed
+type ChangeSource interface {
+	Changes(ctx context.Context, since int64, limit int) (domain.ChangesResponse, error)
+}
@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"context"
+
+	"service-matrix-go/internal/core/domain"
+)
+
+// MutationObserver is told about every completed word list mutation.
+// Observers run synchronously after the change is stored; errors are theirs
+// to report, the mutation has already happened.
+type MutationObserver interface {
+	WordsChanged(ctx context.Context, m domain.Mutation)
+}
@@ -0,0 +1,22 @@
+package ports
+
+import (
+	"context"
+
+	"service-matrix-go/internal/core/domain"
+)
+
+// SnapshotStore keeps numbered snapshots of word lists
+type SnapshotStore interface {
+	// Save stores the lists, assigns the next snapshot ID and returns the
+	// completed snapshot
+	Save(ctx context.Context, snap domain.Snapshot, lists map[string][]string) (domain.Snapshot, error)
+	// List returns all snapshots, oldest first
+	List(ctx context.Context) ([]domain.Snapshot, error)
+	// Get returns one snapshot or an error wrapping domain.ErrNotFound
+	Get(ctx context.Context, id int) (domain.Snapshot, error)
+	// ReadList returns the content of one list of a snapshot
+	ReadList(ctx context.Context, snap domain.Snapshot, name string) ([]string, error)
+	// Delete removes a snapshot and any content no other snapshot uses
+	Delete(ctx context.Context, id int) error
+}
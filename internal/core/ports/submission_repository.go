@@ -0,0 +1,17 @@
+package ports
+
+import (
+	"context"
+
+	"service-matrix-go/internal/core/domain"
+)
+
+// SubmissionRepository stores the moderation queue
+type SubmissionRepository interface {
+	// List returns every submission, oldest first
+	List(ctx context.Context) ([]domain.Submission, error)
+	// Get returns one submission or an error wrapping domain.ErrNotFound
+	Get(ctx context.Context, id string) (domain.Submission, error)
+	// Save inserts or replaces a submission by ID
+	Save(ctx context.Context, sub domain.Submission) error
+}
@@ -0,0 +1,64 @@
+package ports
+
+import "context"
+
+// ListName names a word list kept per language
+type ListName string
+
+const (
+	// ListInclude holds words players asked to add to the dictionary
+	ListInclude ListName = "include"
+	// ListExclude holds words players asked to hide from results
+	ListExclude ListName = "exclude"
+	// ListMerged holds words merged into the dictionary; Search reads it
+	ListMerged ListName = "merged"
+	// ListMergeable holds merge candidates awaiting review
+	ListMergeable ListName = "mergeable"
+	// ListMergedCleaned holds the output of CleanMerge
+	ListMergedCleaned ListName = "merged_cleaned"
+)
+
+// ListRef identifies one word list of one language
+type ListRef struct {
+	Language string
+	Name     ListName
+}
+
+func (r ListRef) String() string {
+	return r.Language + "/" + string(r.Name)
+}
+
+// WordRepository stores named word lists. Words are compared as exact
+// strings; normalisation is the caller's job. Lists keep insertion order and
+// a list that was never written reads as empty.
+type WordRepository interface {
+	// List returns the words of a list in order
+	List(ctx context.Context, ref ListRef) ([]string, error)
+	// Add appends the words not already in the list and returns them
+	Add(ctx context.Context, ref ListRef, words []string) ([]string, error)
+	// Remove deletes the given words, keeping the order of the rest, and
+	// returns the words that were actually removed
+	Remove(ctx context.Context, ref ListRef, words []string) ([]string, error)
+	// Contains reports whether word is in the list
+	Contains(ctx context.Context, ref ListRef, word string) (bool, error)
+	// Replace overwrites the whole list
+	Replace(ctx context.Context, ref ListRef, words []string) error
+}
+
+// SplitRemoved splits list into the entries to keep and the entries matching
+// one of words, preserving order. Backends use it to implement Remove.
+func SplitRemoved(list []string, words []string) (remaining []string, removed []string) {
+	drop := make(map[string]bool, len(words))
+	for _, w := range words {
+		drop[w] = true
+	}
+	remaining = make([]string, 0, len(list))
+	for _, w := range list {
+		if drop[w] {
+			removed = append(removed, w)
+			continue
+		}
+		remaining = append(remaining, w)
+	}
+	return remaining, removed
+}
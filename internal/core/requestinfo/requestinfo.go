@@ -0,0 +1,28 @@
+// Package requestinfo carries who made a request, and which request it was,
+// through the context to the services that record it
+package requestinfo
+
+import "context"
+
+// Info identifies one request
+type Info struct {
+	// RequestID is the caller's X-Request-ID or one generated for the request
+	RequestID string
+	// Client identifies the caller, by default its address
+	Client string
+	// Key names the API key the caller presented, if any
+	Key string
+}
+
+type contextKey struct{}
+
+// WithInfo returns a copy of ctx carrying info
+func WithInfo(ctx context.Context, info Info) context.Context {
+	return context.WithValue(ctx, contextKey{}, info)
+}
+
+// FromContext returns the request info of ctx, or the zero Info outside a request
+func FromContext(ctx context.Context) Info {
+	info, _ := ctx.Value(contextKey{}).(Info)
+	return info
+}
@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"service-matrix-go/internal/core/dictionary"
+	"service-matrix-go/internal/core/domain"
+	"service-matrix-go/internal/core/language"
+	"service-matrix-go/internal/core/normalization"
+	"service-matrix-go/internal/core/ports"
+	"service-matrix-go/internal/core/requestinfo"
+)
+
+// AuditService records every word list mutation in the audit log, with the
+// client and request ID of the request that caused it
+type AuditService struct {
+	log       ports.AuditLog
+	languages *language.Registry
+
+	// mu guards origins, which is built from the log on first use and kept up
+	// to date as entries are appended
+	mu      sync.Mutex
+	origins originIndex
+}
+
+// originIndex holds the newest entry that put each word in each list, by
+// language, list and normalised word. The entries carry no changes.
+type originIndex map[string]map[string]map[string]domain.AuditEntry
+
+func NewAuditService(auditLog ports.AuditLog, languages *language.Registry) *AuditService {
+	return &AuditService{log: auditLog, languages: languages}
+}
+
+// WordsChanged appends the mutation to the audit log
+func (a *AuditService) WordsChanged(ctx context.Context, m domain.Mutation) {
+	info := requestinfo.FromContext(ctx)
+	entry := domain.AuditEntry{
+		Time:      time.Now().UTC(),
+		Operation: m.Operation,
+		Language:  m.Language,
+		Client:    info.Client,
+		Key:       info.Key,
+		RequestID: info.RequestID,
+		Reason:    m.Reason,
+		Changes:   m.Changes,
+	}
+	// Held across the append so a first Origin call can't read the log
+	// before the entry and index it too late
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.log.Append(ctx, entry); err != nil {
+		// The mutation has already happened; losing the entry is only logged
+		slog.ErrorContext(ctx, "appending to the audit log", "operation", m.Operation, "language", m.Language, "err", err)
+		return
+	}
+	if a.origins != nil {
+		a.origins.add(a.languages, entry)
+	}
+}
+
+// Query returns the matching entries, newest first
+func (a *AuditService) Query(ctx context.Context, q domain.AuditQuery) ([]domain.AuditEntry, error) {
+	var normalizer *normalization.Normalizer
+	if q.Language != "" || q.Word != "" {
+		pack, err := a.languages.Get(q.Language)
+		if err != nil {
+			return nil, err
+		}
+		q.Language = pack.Name
+		normalizer = pack.Normalizer()
+	}
+
+	entries, err := a.log.Entries(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := []domain.AuditEntry{}
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		switch {
+		case q.Language != "" && entry.Language != q.Language,
+			q.Operation != "" && entry.Operation != q.Operation,
+			q.Client != "" && entry.Client != q.Client,
+			q.Key != "" && entry.Key != q.Key,
+			q.RequestID != "" && entry.RequestID != q.RequestID,
+			!q.Since.IsZero() && entry.Time.Before(q.Since),
+			!q.Until.IsZero() && !entry.Time.Before(q.Until):
+			continue
+		}
+		if q.List != "" || q.Word != "" {
+			entry.Changes = filterChanges(entry.Changes, q.List, q.Word, normalizer)
+			if len(entry.Changes) == 0 {
+				continue
+			}
+		}
+		res = append(res, entry)
+		if q.Limit > 0 && len(res) == q.Limit {
+			break
+		}
+	}
+	return res, nil
+}
+
+// Origin returns the newest entry that put the word with normalised key in
+// list, without its changes. The log is read once, on the first call.
+func (a *AuditService) Origin(ctx context.Context, pack *language.Pack, list, key string) (domain.AuditEntry, bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.origins == nil {
+		entries, err := a.log.Entries(ctx)
+		if err != nil {
+			return domain.AuditEntry{}, false, err
+		}
+		origins := make(originIndex)
+		for _, entry := range entries {
+			origins.add(a.languages, entry)
+		}
+		a.origins = origins
+	}
+	entry, ok := a.origins[pack.Name][list][key]
+	return entry, ok, nil
+}
+
+// add records entry as the origin of every word it added
+func (o originIndex) add(languages *language.Registry, entry domain.AuditEntry) {
+	pack, err := languages.Get(entry.Language)
+	if err != nil {
+		return
+	}
+	normalizer := pack.Normalizer()
+	changes := entry.Changes
+	entry.Changes = nil
+	for _, change := range changes {
+		for _, line := range change.Added {
+			word, ok := dictionary.ParseLine(line)
+			if !ok {
+				continue
+			}
+			if o[pack.Name] == nil {
+				o[pack.Name] = make(map[string]map[string]domain.AuditEntry)
+			}
+			if o[pack.Name][change.List] == nil {
+				o[pack.Name][change.List] = make(map[string]domain.AuditEntry)
+			}
+			o[pack.Name][change.List][normalizer.Key(word.Word)] = entry
+		}
+	}
+}
+
+// filterChanges keeps the changes to list (if set) and, within them, the
+// words matching word (if set)
+func filterChanges(changes []domain.ListChange, list, word string, normalizer *normalization.Normalizer) []domain.ListChange {
+	var kept []domain.ListChange
+	for _, change := range changes {
+		if list != "" && change.List != list {
+			continue
+		}
+		if word != "" {
+			key := normalizer.Key(word)
+			change.Added = matchingLines(change.Added, key, normalizer)
+			change.Removed = matchingLines(change.Removed, key, normalizer)
+		}
+		if len(change.Added) > 0 || len(change.Removed) > 0 {
+			kept = append(kept, change)
+		}
+	}
+	return kept
+}
+
+func matchingLines(lines []string, key string, normalizer *normalization.Normalizer) []string {
+	var matched []string
+	for _, line := range lines {
+		if entry, ok := dictionary.ParseLine(line); ok && normalizer.Key(entry.Word) == key {
+			matched = append(matched, line)
+		}
+	}
+	return matched
+}
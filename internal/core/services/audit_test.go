@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"service-matrix-go/internal/core/domain"
+	"service-matrix-go/internal/core/language"
+	"service-matrix-go/internal/core/requestinfo"
+)
+
+// countingLog is an in-memory audit log that counts full reads
+type countingLog struct {
+	entries []domain.AuditEntry
+	reads   int
+}
+
+func (l *countingLog) Append(_ context.Context, entry domain.AuditEntry) error {
+	l.entries = append(l.entries, entry)
+	return nil
+}
+
+func (l *countingLog) Entries(context.Context) ([]domain.AuditEntry, error) {
+	l.reads++
+	return l.entries, nil
+}
+
+func TestAuditOrigin(t *testing.T) {
+	languages := language.NewRegistry()
+	pack, _ := languages.Get("ru")
+	log := &countingLog{entries: []domain.AuditEntry{
+		{Operation: domain.OperationAdd, Language: "ru", Changes: []domain.ListChange{{List: "include", Added: []string{"Кот", "дом"}}}},
+		{Operation: domain.OperationAdd, Language: "en", Changes: []domain.ListChange{{List: "include", Added: []string{"cat"}}}},
+	}}
+	audit := NewAuditService(log, languages)
+	ctx := requestinfo.WithInfo(context.Background(), requestinfo.Info{Client: "192.0.2.1"})
+
+	origin := func(list, key string) (domain.AuditEntry, bool) {
+		t.Helper()
+		entry, ok, err := audit.Origin(ctx, pack, list, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return entry, ok
+	}
+
+	if entry, ok := origin("include", "кот"); !ok || entry.Operation != domain.OperationAdd || entry.Changes != nil {
+		t.Errorf("origin of кот = %+v, %v; want the add, without its changes", entry, ok)
+	}
+	if _, ok := origin("include", "cat"); ok {
+		t.Error("an en word is an origin in ru")
+	}
+	if _, ok := origin("exclude", "кот"); ok {
+		t.Error("an include is an origin in the exclude list")
+	}
+
+	// Entries appended later are indexed as they come
+	audit.WordsChanged(ctx, domain.Mutation{Operation: domain.OperationMerge, Language: "ru", Changes: []domain.ListChange{
+		{List: "merged", Added: []string{"дом"}},
+		{List: "include", Removed: []string{"дом"}},
+	}})
+	audit.WordsChanged(ctx, domain.Mutation{Operation: domain.OperationReplicate, Language: "ru", Changes: []domain.ListChange{
+		{List: "include", Added: []string{"кот"}},
+	}})
+	if entry, ok := origin("merged", "дом"); !ok || entry.Operation != domain.OperationMerge || entry.Client != "192.0.2.1" {
+		t.Errorf("origin of the merged дом = %+v, %v; want the merge", entry, ok)
+	}
+	if entry, _ := origin("include", "кот"); entry.Operation != domain.OperationReplicate {
+		t.Errorf("origin of кот = %+v, want the newer replication", entry)
+	}
+	if entry, _ := origin("include", "дом"); entry.Operation != domain.OperationAdd {
+		t.Errorf("origin of the included дом = %+v, want the first add", entry)
+	}
+
+	if log.reads != 1 {
+		t.Errorf("the log was read %d times, want once", log.reads)
+	}
+}
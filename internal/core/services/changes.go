@@ -0,0 +1,224 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"service-matrix-go/internal/core/domain"
+	"service-matrix-go/internal/core/ports"
+	"service-matrix-go/internal/core/requestinfo"
+)
+
+// feedLists are the lists the changes feed carries
+var feedLists = []ports.ListName{ports.ListInclude, ports.ListExclude, ports.ListMerged}
+
+// defaultChangesLimit caps a feed page when the reader gives no limit
+const defaultChangesLimit = 1000
+
+// ChangeFeedService publishes include, exclude and merged mutations as a
+// versioned feed other instances can follow
+type ChangeFeedService struct {
+	words *WordService
+	log   ports.ChangeLog
+}
+
+func NewChangeFeedService(words *WordService, changeLog ports.ChangeLog) *ChangeFeedService {
+	return &ChangeFeedService{words: words, log: changeLog}
+}
+
+// WordsChanged appends the feed lists' part of a mutation to the feed
+func (f *ChangeFeedService) WordsChanged(ctx context.Context, m domain.Mutation) {
+	event := domain.ChangeEvent{Time: time.Now().UTC(), Operation: m.Operation, Language: m.Language}
+	for _, change := range m.Changes {
+		if isFeedList(change.List) && (len(change.Added) > 0 || len(change.Removed) > 0) {
+			event.Changes = append(event.Changes, change)
+		}
+	}
+	if len(event.Changes) == 0 {
+		return
+	}
+	if _, err := f.log.Append(ctx, event); err != nil {
+		slog.ErrorContext(ctx, "appending to the changes feed", "operation", m.Operation, "language", m.Language, "err", err)
+	}
+}
+
+// EnsureBaseline starts an empty feed with the current lists of every
+// language, so a follower starting from version 0 ends up with everything
+func (f *ChangeFeedService) EnsureBaseline(ctx context.Context) error {
+	res, err := f.log.Since(ctx, 0, 1)
+	if err != nil || res.Latest > 0 {
+		return err
+	}
+	for _, name := range f.words.languages.Names() {
+		event := domain.ChangeEvent{Time: time.Now().UTC(), Operation: domain.OperationBaseline, Language: name}
+		for _, list := range feedLists {
+			words, err := f.words.words.List(ctx, ports.ListRef{Language: name, Name: list})
+			if err != nil {
+				return err
+			}
+			event.Changes = append(event.Changes, domain.ListChange{List: string(list), Added: words})
+		}
+		if _, err := f.log.Append(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Since returns up to limit events newer than version
+func (f *ChangeFeedService) Since(ctx context.Context, version int64, limit int) (domain.ChangesResponse, error) {
+	if version < 0 {
+		return domain.ChangesResponse{}, domain.InvalidField("since", "since must not be negative")
+	}
+	if limit <= 0 || limit > defaultChangesLimit {
+		limit = defaultChangesLimit
+	}
+	return f.log.Since(ctx, version, limit)
+}
+
+func isFeedList(name string) bool {
+	for _, list := range feedLists {
+		if string(list) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Follower polls a leader's changes feed and applies each event to the
+// local lists. The last applied version is kept in a state file so a restart
+// resumes where it stopped.
+type Follower struct {
+	words    *WordService
+	leader   ports.ChangeSource
+	name     string
+	interval time.Duration
+
+	stateDir  string
+	stateFile string
+}
+
+// NewFollower follows leader, polling every interval. name identifies the
+// leader in the audit log.
+func NewFollower(words *WordService, leader ports.ChangeSource, name string, interval time.Duration, stateDir, stateFile string) *Follower {
+	return &Follower{words: words, leader: leader, name: name, interval: interval, stateDir: stateDir, stateFile: stateFile}
+}
+
+// Run polls until ctx is cancelled. Failed polls are logged and retried on
+// the next tick.
+func (f *Follower) Run(ctx context.Context) {
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+	for {
+		if err := f.Sync(ctx); err != nil && ctx.Err() == nil {
+			slog.WarnContext(ctx, "following leader", "leader", f.name, "err", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync applies every leader event newer than the last applied version
+func (f *Follower) Sync(ctx context.Context) error {
+	version, err := f.appliedVersion()
+	if err != nil {
+		return err
+	}
+	for {
+		res, err := f.leader.Changes(ctx, version, defaultChangesLimit)
+		if err != nil {
+			return err
+		}
+		if res.Latest < version {
+			return fmt.Errorf("leader is at version %d but %d was already applied; was its feed reset?", res.Latest, version)
+		}
+		for _, event := range res.Changes {
+			// Stop between events, never halfway through one
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := f.apply(ctx, event); err != nil {
+				return fmt.Errorf("applying version %d: %w", event.Version, err)
+			}
+			version = event.Version
+			if err := f.words.fileHelper.WriteFileNewContents([]string{strconv.FormatInt(version, 10)}, f.stateDir, f.stateFile); err != nil {
+				return err
+			}
+		}
+		if len(res.Changes) == 0 || version >= res.Latest {
+			return nil
+		}
+	}
+}
+
+// apply makes one leader event's changes locally. Adds and removes are
+// idempotent, so replaying an event after a crash is harmless; a baseline
+// replaces the lists outright.
+func (f *Follower) apply(ctx context.Context, event domain.ChangeEvent) error {
+	pack, err := f.words.languages.Get(event.Language)
+	if err != nil {
+		return err
+	}
+	ctx = requestinfo.WithInfo(ctx, requestinfo.Info{
+		Client:    "leader " + f.name,
+		RequestID: fmt.Sprintf("%s#%d", f.name, event.Version),
+	})
+
+	// Recorded as a replication, so a follower of this instance sees plain
+	// adds and removes even for the leader's baseline
+	mutation := domain.Mutation{
+		Operation: domain.OperationReplicate,
+		Language:  pack.Name,
+		Reason:    fmt.Sprintf("%s version %d from %s", event.Operation, event.Version, f.name),
+	}
+	for _, change := range event.Changes {
+		if !isFeedList(change.List) {
+			continue
+		}
+		ref := ports.ListRef{Language: pack.Name, Name: ports.ListName(change.List)}
+		applied := domain.ListChange{List: change.List}
+		if event.Operation == domain.OperationBaseline {
+			before, err := f.words.words.List(ctx, ref)
+			if err != nil {
+				return err
+			}
+			if err := f.words.words.Replace(ctx, ref, change.Added); err != nil {
+				return err
+			}
+			applied.Added, applied.Removed = diffLines(before, change.Added)
+		} else {
+			if applied.Removed, err = f.words.words.Remove(ctx, ref, change.Removed); err != nil {
+				return err
+			}
+			if applied.Added, err = f.words.words.Add(ctx, ref, change.Added); err != nil {
+				return err
+			}
+		}
+		mutation.Changes = append(mutation.Changes, applied)
+	}
+	f.words.notify(ctx, mutation)
+	return nil
+}
+
+func (f *Follower) appliedVersion() (int64, error) {
+	lines, err := f.words.fileHelper.ReadFileAsync(f.stateDir, f.stateFile)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	if len(lines) == 0 {
+		return 0, nil
+	}
+	return strconv.ParseInt(strings.TrimSpace(lines[0]), 10, 64)
+}
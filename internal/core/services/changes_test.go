@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"service-matrix-go/internal/core/domain"
+	"service-matrix-go/internal/core/ports"
+	"service-matrix-go/internal/infrastructure/memory"
+)
+
+// staticLeader serves a fixed feed
+type staticLeader []domain.ChangeEvent
+
+func (l staticLeader) Changes(_ context.Context, since int64, limit int) (domain.ChangesResponse, error) {
+	res := domain.ChangesResponse{Changes: []domain.ChangeEvent{}}
+	for _, event := range l {
+		if event.Version > since && len(res.Changes) < limit {
+			res.Changes = append(res.Changes, event)
+		}
+		res.Latest = event.Version
+	}
+	return res, nil
+}
+
+func TestFollowerRefusesLocalChanges(t *testing.T) {
+	s, _ := fileService(t, "кошка\n")
+	ctx := context.Background()
+	include := ports.ListRef{Language: "ru", Name: ports.ListInclude}
+	if _, err := s.UpdateWords(ctx, domain.UpdateWordsRequest{Words: []string{"лес"}, Include: true}); err != nil {
+		t.Fatal(err)
+	}
+	s.FollowLeader("http://leader:8080")
+
+	moderation := NewModerationService(s, memory.NewSubmissionRepository(), 1)
+	snapshots := NewSnapshotService(s, nil, 0, false)
+	changes := map[string]func() error{
+		"UpdateWords": func() error {
+			_, err := s.UpdateWords(ctx, domain.UpdateWordsRequest{Words: []string{"дом"}, Include: true})
+			return err
+		},
+		"Submit": func() error {
+			_, err := moderation.Submit(ctx, domain.UpdateWordsRequest{Words: []string{"дом"}, Include: true, Submitter: "bot"})
+			return err
+		},
+		"RemoveWords": func() error {
+			_, err := s.RemoveWords(ctx, domain.RemoveWordsRequest{Words: []string{"лес"}, Include: true})
+			return err
+		},
+		"RemoveMerged": func() error {
+			_, err := s.RemoveMerged(ctx, domain.RemoveMergedRequest{Words: []string{"лес"}})
+			return err
+		},
+		"PreviewMerge": func() error {
+			_, err := s.PreviewMerge(ctx, "ru")
+			return err
+		},
+		"MergeWords": func() error {
+			_, err := s.MergeWords(ctx, domain.MergeCommitRequest{})
+			return err
+		},
+		"Check with Fix": func() error {
+			_, err := s.Check(ctx, domain.FsckRequest{Fix: true})
+			return err
+		},
+		"Rollback": func() error {
+			_, err := snapshots.Rollback(ctx, domain.RollbackRequest{ID: 1})
+			return err
+		},
+	}
+	for name, change := range changes {
+		if err := change(); !errors.Is(err, domain.ErrConflict) {
+			t.Errorf("%s on a follower: %v, want ErrConflict", name, err)
+		}
+	}
+	if words, _ := s.words.List(ctx, include); !slices.Equal(words, []string{"лес"}) {
+		t.Errorf("include list is %q after refused changes, want лес", words)
+	}
+
+	// Reading is fine, and so is the check without fixing
+	if _, err := s.Check(ctx, domain.FsckRequest{}); err != nil {
+		t.Errorf("Check on a follower: %v", err)
+	}
+
+	// Replication still changes the lists
+	leader := staticLeader{{Version: 1, Operation: domain.OperationAdd, Language: "ru", Changes: []domain.ListChange{
+		{List: string(ports.ListInclude), Added: []string{"дом"}, Removed: []string{"лес"}},
+	}}}
+	follower := NewFollower(s, leader, "leader", 0, "data", "follower_version.txt")
+	if err := follower.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if words, _ := s.words.List(ctx, include); !slices.Equal(words, []string{"дом"}) {
+		t.Errorf("include list is %q after syncing, want дом", words)
+	}
+}
@@ -0,0 +1,329 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"service-matrix-go/internal/core/dictionary"
+	"service-matrix-go/internal/core/language"
+	"service-matrix-go/internal/core/ports"
+	"service-matrix-go/internal/infrastructure/dictindex"
+)
+
+// keyedEntry is a dictionary entry with its normalised comparison key
+type keyedEntry = dictindex.Entry
+
+// indexFileName is the compiled dictionary of a pack, in its data directory
+const indexFileName = "dictionary.idx"
+
+// Inputs of the composed dictionary, as recorded in its fingerprint
+const (
+	sourceDictionary  = "dictionary"
+	sourceFrequencies = "frequencies"
+	sourceMerged      = "merged"
+)
+
+// composedDictionary is the loaded dictionary of one language, parsed into
+// entries or read in place from a compiled index
+type composedDictionary struct {
+	entries []keyedEntry
+	keys    map[string]bool
+	index   *dictindex.Index
+	sources map[string]dictindex.Source
+	// counts is how many entries came from the dictionary and from merged
+	counts map[string]int
+
+	// An index stays open while the dictionary is cached or in use
+	mu      sync.Mutex
+	users   int
+	retired bool
+}
+
+// len returns the number of entries
+func (d *composedDictionary) len() int {
+	if d.index != nil {
+		return d.index.Len()
+	}
+	return len(d.entries)
+}
+
+// contains reports whether key is in the dictionary
+func (d *composedDictionary) contains(key string) bool {
+	if d.index != nil {
+		return d.index.Contains(key)
+	}
+	return d.keys[key]
+}
+
+// each calls fn with every entry, in dictionary order, until it returns false
+func (d *composedDictionary) each(fn func(keyedEntry) bool) error {
+	if d.index != nil {
+		return d.index.Each(fn)
+	}
+	for _, e := range d.entries {
+		if !fn(e) {
+			break
+		}
+	}
+	return nil
+}
+
+// acquire marks the dictionary in use; each acquire needs a release
+func (d *composedDictionary) acquire() *composedDictionary {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.users++
+	return d
+}
+
+// release ends a use, closing the index of a retired dictionary once nothing
+// uses it
+func (d *composedDictionary) release() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.users--
+	d.closeIfUnused()
+}
+
+// retire drops the dictionary from the cache
+func (d *composedDictionary) retire() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.retired = true
+	d.closeIfUnused()
+}
+
+func (d *composedDictionary) closeIfUnused() {
+	if d.retired && d.users == 0 && d.index != nil {
+		d.index.Close()
+	}
+}
+
+// dictionaryCache keeps each language's composed dictionary until one of
+// its sources changes
+type dictionaryCache struct {
+	mu         sync.Mutex
+	byLanguage map[string]*composedDictionary
+}
+
+// UseDictionaryIndex makes the service load compiled dictionary indexes
+// (see CompileIndex) in the given mode, falling back to the text sources
+// when an index is missing or stale
+func (s *WordService) UseDictionaryIndex(mode dictindex.Mode) {
+	s.indexMode = mode
+}
+
+// IndexPath returns where the compiled dictionary of pack is kept
+func (s *WordService) IndexPath(pack *language.Pack) string {
+	return filepath.Join(s.fileHelper.BaseDir, pack.DataDir, indexFileName)
+}
+
+// loadDictionary returns the pack's dictionary composed with merged.txt.
+// Entries are deduplicated on the normalised key, keeping the first one seen
+// (dictionary before merged), and their Word is the cleaned display spelling.
+// Entries without a frequency rank take it from the pack's frequency list.
+//
+// The result is cached until the dictionary, frequency list or merged list
+// changes, and comes from the compiled index when there is a current one.
+// The caller must release it when done.
+func (s *WordService) loadDictionary(ctx context.Context, pack *language.Pack) (*composedDictionary, error) {
+	sources, merged, err := s.dictionarySources(ctx, pack)
+	if err != nil {
+		return nil, err
+	}
+
+	s.dictionaries.mu.Lock()
+	defer s.dictionaries.mu.Unlock()
+	cached := s.dictionaries.byLanguage[pack.Name]
+	if cached != nil && sameSources(cached.sources, sources) {
+		return cached.acquire(), nil
+	}
+
+	from := "index"
+	dict := s.openIndex(ctx, pack, sources)
+	if dict == nil {
+		from = "text"
+		if dict, err = s.parseDictionary(pack, merged); err != nil {
+			return nil, err
+		}
+	}
+	dict.sources = sources
+	s.metrics.dictionaryLoaded(pack.Name, from, dict.counts)
+	slog.InfoContext(ctx, "dictionary loaded", "language", pack.Name, "from", from, "entries", dict.len())
+	if s.dictionaries.byLanguage == nil {
+		s.dictionaries.byLanguage = make(map[string]*composedDictionary)
+	}
+	s.dictionaries.byLanguage[pack.Name] = dict
+	if cached != nil {
+		// Searches still using the old one keep its index open until they finish
+		cached.retire()
+	}
+	return dict.acquire(), nil
+}
+
+// CompileIndex compiles the composed dictionary of a language from the text
+// sources into its index file
+func (s *WordService) CompileIndex(ctx context.Context, lang string) (dictindex.Metadata, string, error) {
+	pack, err := s.languages.Get(lang)
+	if err != nil {
+		return dictindex.Metadata{}, "", err
+	}
+	sources, merged, err := s.dictionarySources(ctx, pack)
+	if err != nil {
+		return dictindex.Metadata{}, "", err
+	}
+	dict, err := s.parseDictionary(pack, merged)
+	if err != nil {
+		return dictindex.Metadata{}, "", err
+	}
+
+	meta := dictindex.Metadata{
+		FormatVersion: dictindex.FormatVersion,
+		Language:      pack.Name,
+		BuiltAt:       time.Now().UTC(),
+		Entries:       dict.len(),
+		Normalization: pack.Normalizer().Options(),
+		Sources:       sources,
+		Counts:        dict.counts,
+	}
+	path := s.IndexPath(pack)
+	return meta, path, dictindex.Write(path, meta, dict.entries)
+}
+
+// openIndex loads the pack's compiled index, or returns nil if indexes are
+// off or the index is missing, stale or unreadable
+func (s *WordService) openIndex(ctx context.Context, pack *language.Pack, sources map[string]dictindex.Source) *composedDictionary {
+	if s.indexMode == "" || s.indexMode == dictindex.ModeOff {
+		return nil
+	}
+	path := s.IndexPath(pack)
+	ix, err := dictindex.Open(path, s.indexMode)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "dictionary index unusable, reading text files", "path", path, "err", err)
+		return nil
+	}
+	if !ix.Metadata().Matches(pack.Name, pack.Normalizer().Options(), sources) {
+		slog.WarnContext(ctx, "dictionary index is stale, reading text files until it is recompiled", "path", path)
+		ix.Close()
+		return nil
+	}
+	return &composedDictionary{index: ix, counts: ix.Metadata().Counts}
+}
+
+// parseDictionary composes the dictionary from the text sources
+func (s *WordService) parseDictionary(pack *language.Pack, merged []string) (*composedDictionary, error) {
+	lines, err := s.fileHelper.ReadFileAsync(pack.ResourceDir, pack.DictionaryFile)
+	if err != nil {
+		return nil, err
+	}
+
+	normalizer := pack.Normalizer()
+	ranks := s.loadFrequencyRanks(pack)
+	seen := make(map[string]bool)
+	counts := make(map[string]int)
+	var entries []keyedEntry
+	for _, source := range []struct {
+		name  string
+		lines []string
+	}{{sourceDictionary, lines}, {sourceMerged, merged}} {
+		for _, entry := range dictionary.ParseLines(source.lines) {
+			key := normalizer.Key(entry.Word)
+			if key == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			entry.Word = normalizer.Clean(entry.Word)
+			if entry.FrequencyRank == 0 {
+				entry.FrequencyRank = ranks[key]
+			}
+			entries = append(entries, keyedEntry{Key: key, Entry: entry})
+			counts[source.name]++
+		}
+	}
+	return &composedDictionary{entries: entries, keys: seen, counts: counts}, nil
+}
+
+// dictionarySources fingerprints the inputs of the composed dictionary and
+// returns the merged list read along the way
+func (s *WordService) dictionarySources(ctx context.Context, pack *language.Pack) (map[string]dictindex.Source, []string, error) {
+	sources := make(map[string]dictindex.Source, 3)
+
+	info, err := os.Stat(s.fileHelper.Path(pack.ResourceDir, pack.DictionaryFile))
+	if err != nil {
+		return nil, nil, err
+	}
+	sources[sourceDictionary] = dictindex.Source{Size: info.Size(), ModTime: info.ModTime().UnixNano()}
+
+	if pack.FrequencyFile != "" {
+		if info, err := os.Stat(s.fileHelper.Path(pack.ResourceDir, pack.FrequencyFile)); err == nil {
+			sources[sourceFrequencies] = dictindex.Source{Size: info.Size(), ModTime: info.ModTime().UnixNano()}
+		}
+	}
+
+	// A missing merged list is empty; one that can't be read is an error,
+	// rather than a dictionary silently missing its merged words
+	merged, err := s.words.List(ctx, ports.ListRef{Language: pack.Name, Name: ports.ListMerged})
+	if err != nil {
+		return nil, nil, fmt.Errorf("reading the merged list: %w", err)
+	}
+	h := sha256.New()
+	for _, line := range merged {
+		h.Write([]byte(line + "\n"))
+	}
+	sources[sourceMerged] = dictindex.Source{SHA256: hex.EncodeToString(h.Sum(nil)), Count: len(merged)}
+	return sources, merged, nil
+}
+
+func sameSources(a, b map[string]dictindex.Source) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, src := range a {
+		if b[name] != src {
+			return false
+		}
+	}
+	return true
+}
+
+// loadFrequencyRanks reads the pack's frequency list, most common word first,
+// into normalised key -> rank (1-based). Anything after a tab is ignored so
+// "word<TAB>count" lists sorted by count work too. A missing list yields no ranks.
+func (s *WordService) loadFrequencyRanks(pack *language.Pack) map[string]int {
+	ranks := make(map[string]int)
+	if pack.FrequencyFile == "" {
+		return ranks
+	}
+	lines, err := s.fileHelper.ReadFileAsync(pack.ResourceDir, pack.FrequencyFile)
+	if err != nil {
+		return ranks
+	}
+
+	normalizer := pack.Normalizer()
+	rank := 0
+	for _, line := range lines {
+		word, _, _ := strings.Cut(line, "\t")
+		key := normalizer.Key(word)
+		if key == "" || strings.HasPrefix(key, "#") {
+			continue
+		}
+		rank++
+		if _, exists := ranks[key]; !exists {
+			ranks[key] = rank
+		}
+	}
+	return ranks
+}
@@ -0,0 +1,120 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"service-matrix-go/internal/core/domain"
+	"service-matrix-go/internal/core/language"
+	"service-matrix-go/internal/infrastructure/dictindex"
+	"service-matrix-go/internal/infrastructure/storage"
+)
+
+// fileService is a WordService over flat files in a temp directory holding
+// a Russian dictionary of dict
+func fileService(t *testing.T, dict string) (*WordService, *storage.FileHelper) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "resources"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "resources", "definitions.txt"), []byte(dict), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fh := storage.NewFileHelper(dir)
+	languages := language.NewRegistry()
+	return NewWordService(fh, storage.NewFileWordRepository(fh, languages, nil), languages), fh
+}
+
+func TestLoadDictionaryMergedList(t *testing.T) {
+	s, fh := fileService(t, "кот\nдом\n")
+	pack, _ := s.languages.Get("ru")
+	ctx := context.Background()
+
+	// No merged list is an empty one
+	dict, err := s.loadDictionary(ctx, pack)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dict.release()
+	if dict.len() != 2 {
+		t.Errorf("got %d entries, want 2", dict.len())
+	}
+
+	if err := fh.WriteFileNewContents([]string{"лес"}, "resources", "merged.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if dict, err = s.loadDictionary(ctx, pack); err != nil {
+		t.Fatal(err)
+	}
+	dict.release()
+	if dict.len() != 3 || !dict.contains("лес") {
+		t.Errorf("got %d entries, want 3 with the merged лес", dict.len())
+	}
+
+	// A damaged merged list fails the load instead of dropping its words
+	if err := os.WriteFile(filepath.Join(fh.BaseDir, "resources", "merged.txt"), []byte("лес\nмышь\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.loadDictionary(ctx, pack); !errors.Is(err, storage.ErrCorruptFile) {
+		t.Errorf("loading with a damaged merged list: %v, want ErrCorruptFile", err)
+	}
+}
+
+func TestLoadDictionaryFromIndex(t *testing.T) {
+	s, fh := fileService(t, "кот\nдом\n")
+	pack, _ := s.languages.Get("ru")
+	ctx := context.Background()
+	if _, _, err := s.CompileIndex(ctx, "ru"); err != nil {
+		t.Fatal(err)
+	}
+	s.UseDictionaryIndex(dictindex.ModeMmap)
+
+	old, err := s.loadDictionary(ctx, pack)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if old.index == nil {
+		t.Fatal("the dictionary wasn't read from the compiled index")
+	}
+	if old.len() != 2 || !old.contains("дом") || old.contains("лес") {
+		t.Errorf("index dictionary has %d entries, contains(дом) %v, contains(лес) %v", old.len(), old.contains("дом"), old.contains("лес"))
+	}
+
+	found, err := s.Search(ctx, domain.SearchRequest{LettersMatrix: [][]string{{"к", "о", "т"}}, MaxWords: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].Word != "кот" {
+		t.Errorf("search through the index found %+v, want кот", found)
+	}
+
+	// A change to the sources replaces the cached dictionary, but the old
+	// index stays open while it is in use
+	if err := fh.WriteFileNewContents([]string{"лес"}, "resources", "merged.txt"); err != nil {
+		t.Fatal(err)
+	}
+	current, err := s.loadDictionary(ctx, pack)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer current.release()
+	if current == old || !current.contains("лес") {
+		t.Fatal("the dictionary wasn't reloaded after merged.txt changed")
+	}
+	var keys []string
+	if err := old.each(func(e keyedEntry) bool { keys = append(keys, e.Key); return true }); err != nil {
+		t.Fatalf("reading the retired dictionary while in use: %v", err)
+	}
+	if len(keys) != 2 {
+		t.Errorf("retired dictionary has %q, want its 2 entries", keys)
+	}
+
+	old.release()
+	if !old.retired || old.users != 0 {
+		t.Errorf("old dictionary retired %v with %d users, want retired and unused", old.retired, old.users)
+	}
+}
@@ -0,0 +1,249 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+
+	"service-matrix-go/internal/core/dictionary"
+	"service-matrix-go/internal/core/domain"
+	"service-matrix-go/internal/core/language"
+	"service-matrix-go/internal/core/normalization"
+	"service-matrix-go/internal/core/ports"
+	"service-matrix-go/internal/infrastructure/storage"
+)
+
+// fsckSource is one file or list the check reads, in check order
+type fsckSource struct {
+	name  string
+	list  ports.ListName // empty for the dictionary file
+	lines []string
+}
+
+// fsckSeen is the first occurrence of a word
+type fsckSeen struct {
+	list  string
+	line  int
+	key   string
+	lower string
+}
+
+// Check reports problems in a language's dictionary file and merged,
+// include and exclude lists. With req.Fix the fixable ones are repaired:
+// stray BOMs, whitespace and decomposed letters are cleaned up, and blank
+// lines, non-alphabet words, duplicates and merged words the dictionary
+// already has are dropped (the first occurrence is kept). Words in both the
+// include and exclude lists are only reported, since only a person can tell
+// which list is right, and so are files that fail their checksum, which
+// are not checked further.
+func (s *WordService) Check(ctx context.Context, req domain.FsckRequest) (domain.FsckReport, error) {
+	pack, err := s.languages.Get(req.Language)
+	if err != nil {
+		return domain.FsckReport{}, err
+	}
+
+	// A file that fails its integrity checks is reported rather than
+	// failing the whole check, and left alone by the fix
+	corrupt := make(map[string]error)
+	definitions, err := s.fileHelper.ReadFileAsync(pack.ResourceDir, pack.DictionaryFile)
+	if errors.Is(err, storage.ErrCorruptFile) {
+		corrupt[domain.FsckListDefinitions] = err
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return domain.FsckReport{}, err
+	}
+	sources := []fsckSource{{name: domain.FsckListDefinitions, lines: definitions}}
+	for _, list := range []ports.ListName{ports.ListMerged, ports.ListInclude, ports.ListExclude} {
+		lines, err := s.words.List(ctx, ports.ListRef{Language: pack.Name, Name: list})
+		if errors.Is(err, storage.ErrCorruptFile) {
+			corrupt[string(list)] = err
+		} else if err != nil {
+			return domain.FsckReport{}, err
+		}
+		sources = append(sources, fsckSource{name: string(list), list: list, lines: lines})
+	}
+
+	c := &fsckChecker{
+		pack:   pack,
+		limit:  req.Limit,
+		seen:   make(map[string]map[string]fsckSeen),
+		report: domain.FsckReport{Language: pack.Name, Issues: []domain.FsckIssue{}, Counts: map[string]int{}},
+	}
+	fixed := make([][]string, len(sources))
+	for i, src := range sources {
+		if err := corrupt[src.name]; err != nil {
+			c.issue(src.name, 0, domain.FsckCorrupt, "", err.Error(), false)
+			continue
+		}
+		fixed[i] = c.checkSource(src)
+	}
+	if !req.Fix {
+		return c.report, nil
+	}
+	if err := s.checkWritable(); err != nil {
+		return domain.FsckReport{}, err
+	}
+
+	mutation := domain.Mutation{Operation: domain.OperationRepair, Language: pack.Name, Reason: "consistency check fix"}
+	for i, src := range sources {
+		if corrupt[src.name] != nil || slices.Equal(src.lines, fixed[i]) {
+			continue
+		}
+		if src.list == "" {
+			err = s.fileHelper.WriteFileNewContents(fixed[i], pack.ResourceDir, pack.DictionaryFile)
+		} else {
+			err = s.words.Replace(ctx, ports.ListRef{Language: pack.Name, Name: src.list}, fixed[i])
+		}
+		if err != nil {
+			return domain.FsckReport{}, fmt.Errorf("fixing %s: %w", src.name, err)
+		}
+		// Dropped duplicates leave the set of lines as it was, so the change
+		// may show nothing added or removed
+		added, removed := diffLines(src.lines, fixed[i])
+		mutation.Changes = append(mutation.Changes, domain.ListChange{List: src.name, Added: added, Removed: removed})
+	}
+	s.notify(ctx, mutation)
+
+	c.report.Fixed = true
+	c.report.Changes = mutation.Changes
+	return c.report, nil
+}
+
+// fsckChecker accumulates the report while the sources are checked in order
+type fsckChecker struct {
+	pack   *language.Pack
+	limit  int
+	seen   map[string]map[string]fsckSeen // list -> ё-folded key -> first occurrence
+	report domain.FsckReport
+}
+
+func (c *fsckChecker) issue(list string, line int, kind, word, detail string, fixable bool) {
+	c.report.Counts[kind]++
+	if c.limit > 0 && len(c.report.Issues) >= c.limit {
+		c.report.Truncated = true
+		return
+	}
+	c.report.Issues = append(c.report.Issues, domain.FsckIssue{
+		List: list, Line: line, Kind: kind, Word: word, Detail: detail, Fixable: fixable,
+	})
+}
+
+// checkSource reports the problems of one source and returns its lines as
+// the fix would leave them
+func (c *fsckChecker) checkSource(src fsckSource) []string {
+	fixed := make([]string, 0, len(src.lines))
+	c.seen[src.name] = make(map[string]fsckSeen)
+	for i, line := range src.lines {
+		if out, keep := c.checkLine(src.name, i+1, line); keep {
+			fixed = append(fixed, out)
+		}
+	}
+	return fixed
+}
+
+// checkLine reports the problems of one line and returns the fixed line, or
+// false if the fix drops it
+func (c *fsckChecker) checkLine(list string, lineNo int, line string) (string, bool) {
+	normalizer := c.pack.Normalizer()
+	foldYo := normalizer.Options().FoldYo
+
+	const bom = "\uFEFF"
+	if strings.Contains(line, bom) {
+		c.issue(list, lineNo, domain.FsckBOM, strings.TrimSpace(strings.ReplaceAll(line, bom, "")), "", true)
+		line = strings.ReplaceAll(line, bom, "")
+	}
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" {
+		c.issue(list, lineNo, domain.FsckEmpty, "", "", true)
+		return "", false
+	}
+	if strings.HasPrefix(trimmed, "#") {
+		return line, true
+	}
+	entry, ok := dictionary.ParseLine(line)
+	if !ok {
+		c.issue(list, lineNo, domain.FsckUnparseable, "", trimmed, false)
+		return line, true
+	}
+
+	word := strings.Join(strings.Fields(entry.Word), " ")
+	if word != entry.Word {
+		c.issue(list, lineNo, domain.FsckWhitespace, word, fmt.Sprintf("written as %q", entry.Word), true)
+	}
+	if composed := normalization.NFC(word); composed != word {
+		c.issue(list, lineNo, domain.FsckNotNFC, composed, "", true)
+		word = composed
+	}
+
+	key := normalizer.Key(word)
+	var bad []string
+	for _, r := range key {
+		if r != '-' && r != ' ' && !c.pack.InAlphabet(r) && !slices.Contains(bad, string(r)) {
+			bad = append(bad, string(r))
+		}
+	}
+	if len(bad) > 0 {
+		c.issue(list, lineNo, domain.FsckNonAlphabet, word, "contains "+strings.Join(bad, " "), true)
+		return "", false
+	}
+
+	lower := strings.ToLower(word)
+	yoKey := strings.ReplaceAll(key, "ё", "е")
+	if first, ok := c.seen[list][yoKey]; ok {
+		if first.lower != lower {
+			c.issue(list, lineNo, domain.FsckYoTwin, word, fmt.Sprintf("%q at line %d", first.lower, first.line), foldYo)
+		} else {
+			c.issue(list, lineNo, domain.FsckDuplicate, word, fmt.Sprintf("first at line %d", first.line), true)
+		}
+		if first.key == key {
+			return "", false
+		}
+	} else {
+		c.seen[list][yoKey] = fsckSeen{list: list, line: lineNo, key: key, lower: lower}
+	}
+
+	if other := c.otherList(list); other != "" {
+		if first, ok := c.seen[other][yoKey]; ok {
+			detail := fmt.Sprintf("%s line %d", first.list, first.line)
+			switch {
+			case first.key != key:
+				c.issue(list, lineNo, domain.FsckYoTwin, word, detail, false)
+			case list == domain.FsckListMerged:
+				c.issue(list, lineNo, domain.FsckInDictionary, word, detail, true)
+				return "", false
+			default:
+				c.issue(list, lineNo, domain.FsckIncludeExclude, word, detail, false)
+			}
+		}
+	}
+
+	if word == entry.Word {
+		return line, true
+	}
+	entry.Word = word
+	switch {
+	case strings.HasPrefix(trimmed, "{"):
+		encoded, err := json.Marshal(entry)
+		if err != nil {
+			return line, true
+		}
+		return string(encoded), true
+	case strings.Contains(line, "\t"):
+		return dictionary.FormatTSV(entry), true
+	}
+	return word, true
+}
+
+// otherList names the list a word in list must not also be in
+func (c *fsckChecker) otherList(list string) string {
+	switch list {
+	case domain.FsckListMerged:
+		return domain.FsckListDefinitions
+	case domain.FsckListExclude:
+		return domain.FsckListInclude
+	}
+	return ""
+}
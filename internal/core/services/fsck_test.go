@@ -0,0 +1,141 @@
+package services
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"service-matrix-go/internal/core/domain"
+	"service-matrix-go/internal/core/ports"
+)
+
+func TestCheckAndFix(t *testing.T) {
+	s, fh := fileService(t, "\uFEFFxxxx\nкот\nдом\n\nкот\n")
+	ctx := context.Background()
+	lists := map[string][]string{
+		"merged.txt":  {"дом", `{"word": `, "белый  гриб", "ёлка"},
+		"include.txt": {"сад", "ель", "Ель", "ёж", "еж"},
+		"exclude.txt": {"сад"},
+	}
+	for name, lines := range lists {
+		dir := "data"
+		if name == "merged.txt" {
+			dir = "resources"
+		}
+		if err := fh.WriteFileNewContents(lines, dir, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wantIssues := []domain.FsckIssue{
+		{List: "definitions", Line: 1, Kind: domain.FsckBOM, Word: "xxxx", Fixable: true},
+		{List: "definitions", Line: 1, Kind: domain.FsckNonAlphabet, Word: "xxxx", Detail: "contains x", Fixable: true},
+		{List: "definitions", Line: 4, Kind: domain.FsckEmpty, Fixable: true},
+		{List: "definitions", Line: 5, Kind: domain.FsckDuplicate, Word: "кот", Detail: "first at line 2", Fixable: true},
+		{List: "merged", Line: 1, Kind: domain.FsckInDictionary, Word: "дом", Detail: "definitions line 3", Fixable: true},
+		{List: "merged", Line: 2, Kind: domain.FsckUnparseable, Detail: `{"word":`},
+		{List: "merged", Line: 3, Kind: domain.FsckWhitespace, Word: "белый гриб", Detail: `written as "белый  гриб"`, Fixable: true},
+		{List: "merged", Line: 4, Kind: domain.FsckNotNFC, Word: "ёлка", Fixable: true},
+		{List: "include", Line: 3, Kind: domain.FsckDuplicate, Word: "Ель", Detail: "first at line 2", Fixable: true},
+		{List: "include", Line: 5, Kind: domain.FsckYoTwin, Word: "еж", Detail: `"ёж" at line 4`, Fixable: true},
+		{List: "exclude", Line: 1, Kind: domain.FsckIncludeExclude, Word: "сад", Detail: "include line 1"},
+	}
+	report, err := s.Check(ctx, domain.FsckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(report.Issues, wantIssues) {
+		t.Errorf("issues:\n%+v\nwant:\n%+v", report.Issues, wantIssues)
+	}
+	if report.Counts[domain.FsckDuplicate] != 2 || report.Fixed {
+		t.Errorf("counts %v, fixed %v; want 2 duplicates and nothing fixed", report.Counts, report.Fixed)
+	}
+
+	// A report only lists up to the limit, but counts everything
+	limited, err := s.Check(ctx, domain.FsckRequest{Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(limited.Issues) != 2 || !limited.Truncated || !reflect.DeepEqual(limited.Counts, report.Counts) {
+		t.Errorf("limited report %+v, want 2 issues, truncated, with full counts", limited)
+	}
+
+	report, err = s.Check(ctx, domain.FsckRequest{Fix: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Fixed {
+		t.Error("fix mode did not report the lists fixed")
+	}
+	for list, want := range map[ports.ListName][]string{
+		ports.ListMerged:  {`{"word": `, "белый гриб", "ёлка"},
+		ports.ListInclude: {"сад", "ель", "ёж"},
+		ports.ListExclude: {"сад"},
+	} {
+		if got, _ := s.words.List(ctx, ports.ListRef{Language: "ru", Name: list}); !slices.Equal(got, want) {
+			t.Errorf("%s list is %q after the fix, want %q", list, got, want)
+		}
+	}
+	if got, _ := fh.ReadFileAsync("resources", "definitions.txt"); !slices.Equal(got, []string{"кот", "дом"}) {
+		t.Errorf("definitions.txt is %q after the fix, want [кот дом]", got)
+	}
+
+	// What only a person can resolve is still there
+	report, err = s.Check(ctx, domain.FsckRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kinds []string
+	for _, issue := range report.Issues {
+		kinds = append(kinds, issue.List+" "+issue.Kind)
+	}
+	want := []string{"merged unparseable", "exclude include_and_exclude"}
+	if !slices.Equal(kinds, want) {
+		t.Errorf("after the fix the check finds %q, want %q", kinds, want)
+	}
+}
+
+func TestCheckReportsCorruptFiles(t *testing.T) {
+	s, fh := fileService(t, "кот\nкот\n")
+	ctx := context.Background()
+	if err := fh.WriteFileNewContents([]string{"сад"}, "data", "include.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := fh.WriteFileNewContents([]string{"сад"}, "data", "exclude.txt"); err != nil {
+		t.Fatal(err)
+	}
+	// Edited without sealing, so it no longer matches its checksum
+	path := filepath.Join(fh.BaseDir, "data", "include.txt")
+	if err := os.WriteFile(path, []byte("сад\nсад\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := s.Check(ctx, domain.FsckRequest{Fix: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kinds []string
+	for _, issue := range report.Issues {
+		kinds = append(kinds, issue.List+" "+issue.Kind)
+	}
+	// The exclude list can't be compared with an include list that can't be read
+	want := []string{"definitions duplicate", "include corrupt"}
+	if !slices.Equal(kinds, want) {
+		t.Errorf("check finds %q, want %q", kinds, want)
+	}
+	if issue := report.Issues[len(report.Issues)-1]; issue.Fixable || !strings.Contains(issue.Detail, "checksum") {
+		t.Errorf("corrupt file reported as %+v, want unfixable with the checksum mismatch", issue)
+	}
+
+	// The fix repairs the rest and leaves the corrupt file as it found it
+	if got, _ := fh.ReadFileAsync("resources", "definitions.txt"); !slices.Equal(got, []string{"кот"}) {
+		t.Errorf("definitions.txt is %q after the fix, want [кот]", got)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "сад\nсад\n" {
+		t.Errorf("include.txt is %q after the fix, want it untouched", data)
+	}
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"service-matrix-go/internal/core/domain"
+	"service-matrix-go/internal/core/language"
+)
+
+// Preload loads the dictionary of every language that has one, so that the
+// first search doesn't pay for it and Ready can report it loaded
+func (s *WordService) Preload(ctx context.Context) error {
+	var errs []error
+	for _, pack := range s.packsWithDictionary() {
+		dict, err := s.loadDictionary(ctx, pack)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("loading %s dictionary: %w", pack.Name, err))
+			continue
+		}
+		dict.release()
+	}
+	return errors.Join(errs...)
+}
+
+// Ready checks that every language's dictionary is loaded and that every
+// data directory is writable
+func (s *WordService) Ready() []domain.HealthCheck {
+	var checks []domain.HealthCheck
+
+	s.dictionaries.mu.Lock()
+	loaded := make(map[string]bool, len(s.dictionaries.byLanguage))
+	for name := range s.dictionaries.byLanguage {
+		loaded[name] = true
+	}
+	s.dictionaries.mu.Unlock()
+	for _, pack := range s.packsWithDictionary() {
+		check := domain.HealthCheck{Name: "dictionary " + pack.Name, OK: loaded[pack.Name]}
+		if !check.OK {
+			check.Error = "not loaded yet"
+		}
+		checks = append(checks, check)
+	}
+
+	dirs := make(map[string]bool)
+	for _, name := range s.languages.Names() {
+		pack, _ := s.languages.Get(name)
+		dirs[pack.DataDir] = true
+	}
+	sorted := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		sorted = append(sorted, dir)
+	}
+	sort.Strings(sorted)
+	for _, dir := range sorted {
+		check := domain.HealthCheck{Name: "writable " + dir, OK: true}
+		if err := checkWritable(filepath.Join(s.fileHelper.BaseDir, dir)); err != nil {
+			check.OK = false
+			check.Error = err.Error()
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// packsWithDictionary returns the packs whose dictionary file exists
+func (s *WordService) packsWithDictionary() []*language.Pack {
+	var packs []*language.Pack
+	for _, name := range s.languages.Names() {
+		pack, _ := s.languages.Get(name)
+		if _, err := os.Stat(s.fileHelper.Path(pack.ResourceDir, pack.DictionaryFile)); err == nil {
+			packs = append(packs, pack)
+		}
+	}
+	return packs
+}
+
+// checkWritable creates and removes a file in dir, creating dir if needed
+func checkWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".ready-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	f.Close()
+	return os.Remove(name)
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"service-matrix-go/internal/core/domain"
+	"service-matrix-go/internal/metrics"
+)
+
+// Metrics are what the services report on /metrics. The methods do nothing
+// on a nil *Metrics, so services work without one.
+type Metrics struct {
+	searchDuration    *metrics.Histogram
+	wordsFound        *metrics.Histogram
+	dictionaryEntries *metrics.Gauge
+	dictionaryLoads   *metrics.Counter
+	mutations         *metrics.Counter
+	mutatedWords      *metrics.Counter
+}
+
+// NewMetrics registers the service metrics in reg
+func NewMetrics(reg *metrics.Registry) *Metrics {
+	return &Metrics{
+		searchDuration: reg.NewHistogram("matrix_search_duration_seconds",
+			"Time taken by board searches, by board size: small up to 25 cells, medium up to 100, large above.",
+			metrics.DefaultBuckets, "language", "board"),
+		wordsFound: reg.NewHistogram("matrix_search_words_found",
+			"Words found per search, before the maxWords cap.",
+			[]float64{0, 1, 5, 10, 25, 50, 100, 250, 500, 1000}, "language"),
+		dictionaryEntries: reg.NewGauge("matrix_dictionary_entries",
+			"Entries in the composed dictionary, by the source they came from.",
+			"language", "source"),
+		dictionaryLoads: reg.NewCounter("matrix_dictionary_loads_total",
+			"Dictionary (re)loads, from the compiled index or the text files.",
+			"language", "from"),
+		mutations: reg.NewCounter("matrix_word_list_mutations_total",
+			"Completed word list mutations.",
+			"language", "operation"),
+		mutatedWords: reg.NewCounter("matrix_word_list_words_changed_total",
+			"Words added to or removed from each word list.",
+			"language", "list", "change"),
+	}
+}
+
+// searched records one completed search
+func (m *Metrics) searched(lang string, rows, cols int, took time.Duration, found int) {
+	if m == nil {
+		return
+	}
+	m.searchDuration.Observe(took.Seconds(), lang, boardSize(rows, cols))
+	m.wordsFound.Observe(float64(found), lang)
+}
+
+// boardSize buckets a board by its cells, widest row times rows, so the
+// label has three values however many board shapes are searched
+func boardSize(rows, cols int) string {
+	switch cells := rows * cols; {
+	case cells <= 25:
+		return "small"
+	case cells <= 100:
+		return "medium"
+	default:
+		return "large"
+	}
+}
+
+// dictionaryLoaded records a (re)load of a language's dictionary
+func (m *Metrics) dictionaryLoaded(lang, from string, counts map[string]int) {
+	if m == nil {
+		return
+	}
+	m.dictionaryLoads.Inc(lang, from)
+	for _, source := range []string{sourceDictionary, sourceMerged} {
+		m.dictionaryEntries.Set(float64(counts[source]), lang, source)
+	}
+}
+
+// WordsChanged counts the mutation and the words it changed
+func (m *Metrics) WordsChanged(_ context.Context, mut domain.Mutation) {
+	if m == nil {
+		return
+	}
+	m.mutations.Inc(mut.Language, mut.Operation)
+	for _, change := range mut.Changes {
+		if len(change.Added) > 0 {
+			m.mutatedWords.Add(float64(len(change.Added)), mut.Language, change.List, "added")
+		}
+		if len(change.Removed) > 0 {
+			m.mutatedWords.Add(float64(len(change.Removed)), mut.Language, change.List, "removed")
+		}
+	}
+}
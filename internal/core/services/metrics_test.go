@@ -0,0 +1,24 @@
+package services
+
+import "testing"
+
+func TestBoardSize(t *testing.T) {
+	tests := []struct {
+		rows, cols int
+		want       string
+	}{
+		{1, 1, "small"},
+		{4, 4, "small"},
+		{5, 5, "small"},
+		{1, 25, "small"},
+		{5, 6, "medium"},
+		{10, 10, "medium"},
+		{10, 11, "large"},
+		{50, 50, "large"},
+	}
+	for _, tt := range tests {
+		if got := boardSize(tt.rows, tt.cols); got != tt.want {
+			t.Errorf("boardSize(%d, %d) = %q, want %q", tt.rows, tt.cols, got, tt.want)
+		}
+	}
+}
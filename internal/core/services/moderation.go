@@ -0,0 +1,215 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"service-matrix-go/internal/core/domain"
+	"service-matrix-go/internal/core/ports"
+)
+
+// ModerationService queues player submissions for the include and exclude
+// lists until a moderator approves or rejects them. A pending word that
+// collects autoApproveVotes votes from distinct submitters is approved
+// automatically; a threshold of 1 approves every submission straight away.
+type ModerationService struct {
+	words            *WordService
+	submissions      ports.SubmissionRepository
+	autoApproveVotes int
+
+	// mu serialises queue updates so votes aren't lost between read and save
+	mu sync.Mutex
+}
+
+func NewModerationService(words *WordService, submissions ports.SubmissionRepository, autoApproveVotes int) *ModerationService {
+	if autoApproveVotes < 1 {
+		autoApproveVotes = 1
+	}
+	return &ModerationService{words: words, submissions: submissions, autoApproveVotes: autoApproveVotes}
+}
+
+// Submit queues each word, or adds a vote to the pending submission of the
+// same word. Words already in the target list are skipped.
+func (m *ModerationService) Submit(ctx context.Context, req domain.UpdateWordsRequest) (domain.SubmitWordsResponse, error) {
+	if err := m.words.checkWritable(); err != nil {
+		return domain.SubmitWordsResponse{}, err
+	}
+	pack, err := m.words.languages.Get(req.Language)
+	if err != nil {
+		return domain.SubmitWordsResponse{}, err
+	}
+	normalizer := pack.Normalizer()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, err := m.words.words.List(ctx, wordList(pack, req.Include))
+	if err != nil {
+		return domain.SubmitWordsResponse{}, err
+	}
+	inList := make(map[string]bool)
+	for _, w := range existing {
+		inList[normalizer.Key(w)] = true
+	}
+
+	subs, err := m.submissions.List(ctx)
+	if err != nil {
+		return domain.SubmitWordsResponse{}, err
+	}
+	pending := make(map[string]domain.Submission)
+	for _, sub := range subs {
+		if sub.Status == domain.SubmissionPending && sub.Language == pack.Name && sub.Include == req.Include {
+			pending[normalizer.Key(sub.Word)] = sub
+		}
+	}
+
+	res := domain.SubmitWordsResponse{Submissions: []domain.Submission{}, Skipped: []string{}}
+	now := time.Now().UTC()
+	for _, w := range req.Words {
+		key := normalizer.Key(w)
+		if key == "" || inList[key] {
+			res.Skipped = append(res.Skipped, w)
+			continue
+		}
+
+		sub, ok := pending[key]
+		if !ok {
+			id, err := newSubmissionID()
+			if err != nil {
+				return domain.SubmitWordsResponse{}, err
+			}
+			sub = domain.Submission{
+				ID:          id,
+				Word:        normalizer.Clean(w),
+				Language:    pack.Name,
+				Include:     req.Include,
+				Status:      domain.SubmissionPending,
+				Submitter:   req.Submitter,
+				Reason:      req.Reason,
+				SubmittedAt: now,
+			}
+		}
+		if !slices.Contains(sub.Voters, req.Submitter) {
+			sub.Voters = append(sub.Voters, req.Submitter)
+			sub.Votes = len(sub.Voters)
+		}
+
+		if sub.Votes >= m.autoApproveVotes {
+			sub, err = m.approve(ctx, sub, "auto", fmt.Sprintf("reached %d votes", sub.Votes))
+			if err != nil {
+				return domain.SubmitWordsResponse{}, err
+			}
+			sub.AutoApproved = true
+		}
+		if err := m.submissions.Save(ctx, sub); err != nil {
+			return domain.SubmitWordsResponse{}, err
+		}
+		pending[key] = sub
+		res.Submissions = append(res.Submissions, sub)
+	}
+	return res, nil
+}
+
+// List returns submissions, optionally narrowed to one status and language
+func (m *ModerationService) List(ctx context.Context, status string, lang string) ([]domain.Submission, error) {
+	subs, err := m.submissions.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if lang != "" {
+		pack, err := m.words.languages.Get(lang)
+		if err != nil {
+			return nil, err
+		}
+		lang = pack.Name
+	}
+
+	res := []domain.Submission{}
+	for _, sub := range subs {
+		if status != "" && string(sub.Status) != status {
+			continue
+		}
+		if lang != "" && sub.Language != lang {
+			continue
+		}
+		res = append(res, sub)
+	}
+	return res, nil
+}
+
+// Approve moves a pending submission's word into its list
+func (m *ModerationService) Approve(ctx context.Context, req domain.SubmissionDecisionRequest, moderator string) (domain.Submission, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sub, err := m.pendingSubmission(ctx, req.ID)
+	if err != nil {
+		return domain.Submission{}, err
+	}
+	sub, err = m.approve(ctx, sub, moderator, req.Reason)
+	if err != nil {
+		return domain.Submission{}, err
+	}
+	return sub, m.submissions.Save(ctx, sub)
+}
+
+// Reject closes a pending submission without touching the lists
+func (m *ModerationService) Reject(ctx context.Context, req domain.SubmissionDecisionRequest, moderator string) (domain.Submission, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sub, err := m.pendingSubmission(ctx, req.ID)
+	if err != nil {
+		return domain.Submission{}, err
+	}
+	now := time.Now().UTC()
+	sub.Status = domain.SubmissionRejected
+	sub.DecidedAt = &now
+	sub.DecidedBy = moderator
+	sub.DecisionReason = req.Reason
+	return sub, m.submissions.Save(ctx, sub)
+}
+
+func (m *ModerationService) pendingSubmission(ctx context.Context, id string) (domain.Submission, error) {
+	sub, err := m.submissions.Get(ctx, id)
+	if err != nil {
+		return domain.Submission{}, err
+	}
+	if sub.Status != domain.SubmissionPending {
+		return domain.Submission{}, fmt.Errorf("%w: submission %q is already %s", domain.ErrConflict, id, sub.Status)
+	}
+	return sub, nil
+}
+
+// approve adds the word to its list and marks sub approved; the caller saves it
+func (m *ModerationService) approve(ctx context.Context, sub domain.Submission, moderator, reason string) (domain.Submission, error) {
+	_, err := m.words.UpdateWords(ctx, domain.UpdateWordsRequest{
+		Words:     []string{sub.Word},
+		Include:   sub.Include,
+		Language:  sub.Language,
+		Submitter: sub.Submitter,
+		Reason:    sub.Reason,
+	})
+	if err != nil {
+		return domain.Submission{}, err
+	}
+	now := time.Now().UTC()
+	sub.Status = domain.SubmissionApproved
+	sub.DecidedAt = &now
+	sub.DecidedBy = moderator
+	sub.DecisionReason = reason
+	return sub, nil
+}
+
+func newSubmissionID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
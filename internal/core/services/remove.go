@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+
+	"service-matrix-go/internal/core/dictionary"
+	"service-matrix-go/internal/core/domain"
+	"service-matrix-go/internal/core/language"
+	"service-matrix-go/internal/core/ports"
+)
+
+// RemoveWords takes words back out of the include or exclude list
+func (s *WordService) RemoveWords(ctx context.Context, req domain.RemoveWordsRequest) (domain.RemoveWordsResponse, error) {
+	pack, err := s.languages.Get(req.Language)
+	if err != nil {
+		return domain.RemoveWordsResponse{}, err
+	}
+	return s.removeFromList(ctx, pack, wordList(pack, req.Include), req.Words)
+}
+
+// RemoveMerged drops words from merged.txt, so Search stops finding them
+// unless they are also in the base dictionary
+func (s *WordService) RemoveMerged(ctx context.Context, req domain.RemoveMergedRequest) (domain.RemoveWordsResponse, error) {
+	pack, err := s.languages.Get(req.Language)
+	if err != nil {
+		return domain.RemoveWordsResponse{}, err
+	}
+	return s.removeFromList(ctx, pack, ports.ListRef{Language: pack.Name, Name: ports.ListMerged}, req.Words)
+}
+
+// removeFromList removes every entry whose normalised word matches one of
+// words. Entries are matched on the word alone, so rich dictionary lines in
+// merged.txt are removed whole.
+func (s *WordService) removeFromList(ctx context.Context, pack *language.Pack, list ports.ListRef, words []string) (domain.RemoveWordsResponse, error) {
+	if err := s.checkWritable(); err != nil {
+		return domain.RemoveWordsResponse{}, err
+	}
+	normalizer := pack.Normalizer()
+
+	requested := make(map[string]string)
+	for _, w := range words {
+		if key := normalizer.Key(w); key != "" {
+			requested[key] = w
+		}
+	}
+
+	existing, err := s.words.List(ctx, list)
+	if err != nil {
+		return domain.RemoveWordsResponse{}, err
+	}
+	matched := make(map[string]bool)
+	var toRemove []string
+	for _, line := range existing {
+		entry, ok := dictionary.ParseLine(line)
+		if !ok {
+			continue
+		}
+		key := normalizer.Key(entry.Word)
+		if _, ok := requested[key]; ok {
+			matched[key] = true
+			toRemove = append(toRemove, line)
+		}
+	}
+
+	removed, err := s.words.Remove(ctx, list, toRemove)
+	if err != nil {
+		return domain.RemoveWordsResponse{}, err
+	}
+	s.notify(ctx, domain.Mutation{
+		Operation: domain.OperationRemove,
+		Language:  pack.Name,
+		Changes:   []domain.ListChange{{List: string(list.Name), Removed: removed}},
+	})
+
+	res := domain.RemoveWordsResponse{Removed: []string{}, NotFound: []string{}}
+	for _, line := range removed {
+		entry, _ := dictionary.ParseLine(line)
+		res.Removed = append(res.Removed, normalizer.Clean(entry.Word))
+	}
+	res.RemovedCount = len(res.Removed)
+	for _, w := range words {
+		if key := normalizer.Key(w); !matched[key] {
+			res.NotFound = append(res.NotFound, w)
+		}
+	}
+	return res, nil
+}
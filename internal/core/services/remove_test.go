@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"testing"
+
+	"service-matrix-go/internal/core/domain"
+	"service-matrix-go/internal/core/ports"
+)
+
+// mutations records the mutations a service reports
+type mutations []domain.Mutation
+
+func (m *mutations) WordsChanged(_ context.Context, mutation domain.Mutation) {
+	*m = append(*m, mutation)
+}
+
+func TestRemoveWords(t *testing.T) {
+	s, fh := fileService(t, "дом\n")
+	ctx := context.Background()
+	var seen mutations
+	s.Observe(&seen)
+	include := ports.ListRef{Language: "ru", Name: ports.ListInclude}
+	if err := fh.WriteFileNewContents([]string{"Ёлка", "кот", "лес"}, "data", "include.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Words match on their normalised key, whatever their case, ё or spacing
+	res, err := s.RemoveWords(ctx, domain.RemoveWordsRequest{Words: []string{"ЕЛКА", " кот ", "дом"}, Include: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := domain.RemoveWordsResponse{Removed: []string{"Ёлка", "кот"}, RemovedCount: 2, NotFound: []string{"дом"}}
+	if !reflect.DeepEqual(res, want) {
+		t.Errorf("RemoveWords = %+v, want %+v", res, want)
+	}
+	if words, _ := s.words.List(ctx, include); !slices.Equal(words, []string{"лес"}) {
+		t.Errorf("include list is %q, want [лес]", words)
+	}
+	wantMutation := domain.Mutation{
+		Operation: domain.OperationRemove,
+		Language:  "ru",
+		Changes:   []domain.ListChange{{List: "include", Removed: []string{"Ёлка", "кот"}}},
+	}
+	if len(seen) != 1 || !reflect.DeepEqual(seen[0], wantMutation) {
+		t.Errorf("observers saw %+v, want %+v", seen, wantMutation)
+	}
+
+	// Removing only missing words changes nothing and reports nothing
+	res, err = s.RemoveWords(ctx, domain.RemoveWordsRequest{Words: []string{"кот"}, Include: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = domain.RemoveWordsResponse{Removed: []string{}, NotFound: []string{"кот"}}
+	if !reflect.DeepEqual(res, want) {
+		t.Errorf("removing a missing word = %+v, want %+v", res, want)
+	}
+	if len(seen) != 1 {
+		t.Errorf("removing a missing word notified observers: %+v", seen[1:])
+	}
+}
+
+func TestRemoveMerged(t *testing.T) {
+	s, fh := fileService(t, "дом\n")
+	ctx := context.Background()
+	var seen mutations
+	s.Observe(&seen)
+	if err := fh.WriteFileNewContents([]string{"кот\tживотное\tnoun", "ток"}, "resources", "merged.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if got := foundWords(t, s, domain.SearchRequest{}); !slices.Equal(got, []string{"кот", "ток"}) {
+		t.Fatalf("found %q before removing, want [кот ток]", got)
+	}
+
+	// Rich dictionary lines are removed whole
+	res, err := s.RemoveMerged(ctx, domain.RemoveMergedRequest{Words: []string{"КОТ"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(res.Removed, []string{"кот"}) || len(res.NotFound) != 0 {
+		t.Errorf("RemoveMerged = %+v, want кот removed", res)
+	}
+	if len(seen) != 1 || !slices.Equal(seen[0].Changes[0].Removed, []string{"кот\tживотное\tnoun"}) {
+		t.Errorf("observers saw %+v, want the whole кот line removed from merged", seen)
+	}
+
+	// The cached dictionary is dropped, so Search stops finding the word
+	if got := foundWords(t, s, domain.SearchRequest{}); !slices.Equal(got, []string{"ток"}) {
+		t.Errorf("found %q after removing кот, want [ток]", got)
+	}
+}
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"service-matrix-go/internal/core/domain"
+)
+
+// searchWords runs a search for board over s and returns the words found, in
+// the order returned
+func searchWords(t *testing.T, s *WordService, board [][]string) []domain.FoundWord {
+	t.Helper()
+	found, err := s.Search(context.Background(), domain.SearchRequest{LettersMatrix: board, Language: "ru", MaxWords: 1000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return found
+}
+
+// TestSearchStandardBoards pins the words and paths found on standard 5×5
+// boards to those found before rows could be jagged or masked.
+// testdata/words.txt holds every word those searches found, plus words whose
+// letters are all on a board but can't be traced on it, in dictionary order.
+func TestSearchStandardBoards(t *testing.T) {
+	dict, err := os.ReadFile("testdata/words.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile("testdata/boards.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var boards []struct {
+		LettersMatrix [][]string `json:"lettersMatrix"`
+		Words         []struct {
+			Word string                    `json:"word"`
+			Path map[int]map[string]string `json:"path"`
+		} `json:"words"`
+	}
+	if err := json.Unmarshal(data, &boards); err != nil {
+		t.Fatal(err)
+	}
+	s, _ := fileService(t, string(dict))
+
+	for i, board := range boards {
+		found := searchWords(t, s, board.LettersMatrix)
+		if len(found) != len(board.Words) {
+			t.Errorf("board %d: found %d words, want %d", i, len(found), len(board.Words))
+		}
+		for j := range min(len(found), len(board.Words)) {
+			got, want := found[j], board.Words[j]
+			if got.Word != want.Word || !reflect.DeepEqual(got.Path, want.Path) {
+				t.Errorf("board %d, word %d: got %s along %v, want %s along %v", i, j, got.Word, got.Path, want.Word, want.Path)
+			}
+		}
+	}
+}
+
+func TestSearchOddShapes(t *testing.T) {
+	s, _ := fileService(t, "кот\nток\nкит\nоко\nтот\nкто\n")
+	tests := []struct {
+		name  string
+		board [][]string
+		want  []string
+	}{
+		{"rectangle", [][]string{{"к", "о"}, {"т", "и"}}, []string{"кот", "ток", "кит", "кто"}},
+		// и sits under к only, too far from т
+		{"short row", [][]string{{"к", "о", "т"}, {"и"}}, []string{"кот", "ток"}},
+		{"long row", [][]string{{"к"}, {"о", "т"}}, []string{"кот", "ток", "кто"}},
+		{"empty row between", [][]string{{"к", "о"}, {}, {"т"}}, nil},
+		// A mask breaks the only path from о to т
+		{"masked cell", [][]string{{"к", "о", "#", "т"}}, nil},
+		{"masked corner", [][]string{{"#", "к"}, {"т", "о"}}, []string{"кот", "ток", "кто"}},
+		{"all masked but one", [][]string{{"#", "#"}, {"#", "к"}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, w := range searchWords(t, s, tt.board) {
+				got = append(got, w.Word)
+			}
+			slices.Sort(got)
+			want := slices.Clone(tt.want)
+			slices.Sort(want)
+			if !slices.Equal(got, want) {
+				t.Errorf("found %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestSearchRejectsBadBoards(t *testing.T) {
+	s, _ := fileService(t, "кот\n")
+	tests := []struct {
+		name  string
+		board [][]string
+		field string
+	}{
+		{"no rows", nil, "lettersMatrix"},
+		{"only empty rows", [][]string{{}, {}}, "lettersMatrix"},
+		{"only masks", [][]string{{"#"}, {"#", "#"}}, "lettersMatrix"},
+		{"empty cell", [][]string{{"к", "о"}, {"т", ""}}, "lettersMatrix[1][1]"},
+		{"not a letter", [][]string{{"к", "o"}}, "lettersMatrix[0][1]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.Search(context.Background(), domain.SearchRequest{LettersMatrix: tt.board, Language: "ru", MaxWords: 10})
+			var fieldErr *domain.FieldError
+			if !errors.As(err, &fieldErr) || fieldErr.Field != tt.field {
+				t.Errorf("Search: %v, want an error on %s", err, tt.field)
+			}
+		})
+	}
+}
+
+// foundWords runs req on the board of кот, ток, кто and от and returns the
+// words found, in order
+func foundWords(t *testing.T, s *WordService, req domain.SearchRequest) []string {
+	t.Helper()
+	req.LettersMatrix = [][]string{{"к", "о"}, {"#", "т"}}
+	if req.MaxWords == 0 {
+		req.MaxWords = 10
+	}
+	found, err := s.Search(context.Background(), req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	words := []string{}
+	for _, w := range found {
+		words = append(words, w.Word)
+	}
+	return words
+}
+
+func TestSearchMaxWords(t *testing.T) {
+	s, _ := fileService(t, "кот\nток\nкто\nот\n")
+	s.UseSearchSettings(SearchSettings{MaxWords: 2})
+	ctx := context.Background()
+	board := [][]string{{"к", "о"}, {"#", "т"}}
+
+	// 0 is the same as leaving maxWords out: the server default applies
+	for _, tt := range []struct {
+		maxWords domain.FlexInt
+		want     int
+	}{{0, 2}, {3, 3}, {10, 4}} {
+		found, err := s.Search(ctx, domain.SearchRequest{LettersMatrix: board, MaxWords: tt.maxWords})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(found) != tt.want {
+			t.Errorf("maxWords %d found %d words, want %d", tt.maxWords, len(found), tt.want)
+		}
+	}
+
+	var field *domain.FieldError
+	if _, err := s.Search(ctx, domain.SearchRequest{LettersMatrix: board, MaxWords: -1}); !errors.As(err, &field) || field.Field != "maxWords" {
+		t.Errorf("maxWords -1: %v, want a maxWords field error", err)
+	}
+}
+
+func TestSearchFilters(t *testing.T) {
+	s, _ := fileService(t, "кот\tживотное\tnoun\t\tanimal,home\n"+
+		`{"word": "ток", "partOfSpeech": "Noun", "tags": ["physics", "slang"]}`+"\n"+
+		"кто\t\tpronoun\n"+
+		"от\n")
+	tests := []struct {
+		name    string
+		filters domain.SearchFilters
+		want    []string
+	}{
+		{"none", domain.SearchFilters{}, []string{"кот", "ток", "кто", "от"}},
+		// Entries without metadata never pass an allow list
+		{"allow a part of speech", domain.SearchFilters{AllowPartsOfSpeech: []string{"noun"}}, []string{"кот", "ток"}},
+		{"allow two", domain.SearchFilters{AllowPartsOfSpeech: []string{"PRONOUN", "verb", "noun"}}, []string{"кот", "ток", "кто"}},
+		// but always pass a deny list
+		{"deny a part of speech", domain.SearchFilters{DenyPartsOfSpeech: []string{"NOUN"}}, []string{"кто", "от"}},
+		{"allow a tag", domain.SearchFilters{AllowTags: []string{"Animal"}}, []string{"кот"}},
+		{"allow any of the tags", domain.SearchFilters{AllowTags: []string{"home", "physics"}}, []string{"кот", "ток"}},
+		{"deny a tag", domain.SearchFilters{DenyTags: []string{"slang"}}, []string{"кот", "кто", "от"}},
+		{"allow and deny", domain.SearchFilters{AllowPartsOfSpeech: []string{"noun"}, DenyTags: []string{"slang"}}, []string{"кот"}},
+		{"nothing left", domain.SearchFilters{AllowTags: []string{"animal"}, DenyPartsOfSpeech: []string{"noun"}}, []string{}},
+		{"unknown value", domain.SearchFilters{AllowPartsOfSpeech: []string{"adverb"}}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := foundWords(t, s, domain.SearchRequest{Language: "ru", Filters: tt.filters}); !slices.Equal(got, tt.want) {
+				t.Errorf("found %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchTiers(t *testing.T) {
+	s, fh := fileService(t, "кот\t\t\t1\nток\nкто\nот\n")
+	// Ranks come from the list unless the dictionary gives one, so кот
+	// keeps 1 and ток, not listed, has none
+	if err := os.WriteFile(filepath.Join(fh.BaseDir, "resources", "frequencies.txt"), []byte("от\t900\nкто\t500\nкот\t100\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pack, _ := s.languages.Get("ru")
+	pack.CommonRankLimit, pack.MediumRankLimit = 1, 2
+
+	found, err := s.Search(context.Background(), domain.SearchRequest{LettersMatrix: [][]string{{"к", "о"}, {"#", "т"}}, MaxWords: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tiers := make(map[string]domain.Tier)
+	ranks := make(map[string]int)
+	for _, w := range found {
+		tiers[w.Word], ranks[w.Word] = w.Tier, w.FrequencyRank
+	}
+	wantTiers := map[string]domain.Tier{"кот": domain.TierCommon, "от": domain.TierCommon, "кто": domain.TierMedium, "ток": domain.TierRare}
+	wantRanks := map[string]int{"кот": 1, "от": 1, "кто": 2, "ток": 0}
+	if !reflect.DeepEqual(tiers, wantTiers) || !reflect.DeepEqual(ranks, wantRanks) {
+		t.Errorf("tiers %v and ranks %v, want %v and %v", tiers, ranks, wantTiers, wantRanks)
+	}
+
+	tests := []struct {
+		name string
+		req  domain.SearchRequest
+		want []string
+	}{
+		{"longest first", domain.SearchRequest{}, []string{"кот", "ток", "кто", "от"}},
+		// Ties go to the longer word, and unranked words come last
+		{"most common first", domain.SearchRequest{SortBy: domain.SortByFrequency}, []string{"кот", "от", "кто", "ток"}},
+		{"common only", domain.SearchRequest{MaxRarity: "common"}, []string{"кот", "от"}},
+		{"up to medium", domain.SearchRequest{MaxRarity: "medium"}, []string{"кот", "кто", "от"}},
+		{"up to rare", domain.SearchRequest{MaxRarity: "rare"}, []string{"кот", "ток", "кто", "от"}},
+		{"cut off then sorted", domain.SearchRequest{MaxRarity: "medium", SortBy: domain.SortByFrequency, MaxWords: 2}, []string{"кот", "от"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := foundWords(t, s, tt.req); !slices.Equal(got, tt.want) {
+				t.Errorf("found %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// The service defaults apply when the request leaves them out
+	s.UseSearchSettings(SearchSettings{MaxWords: 10, SortBy: domain.SortByFrequency, MaxRarity: "medium"})
+	if got := foundWords(t, s, domain.SearchRequest{}); !slices.Equal(got, []string{"кот", "от", "кто"}) {
+		t.Errorf("with frequency and medium defaults found %q", got)
+	}
+}
@@ -0,0 +1,418 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"service-matrix-go/internal/core/dictionary"
+	"service-matrix-go/internal/core/domain"
+	"service-matrix-go/internal/core/language"
+	"service-matrix-go/internal/core/ports"
+)
+
+// Snapshot triggers
+const (
+	triggerBaseline = "baseline"
+	triggerManual   = "manual"
+)
+
+// snapshotLists maps the restorable lists of a snapshot to their word lists.
+// The base dictionary is recorded too but never written back.
+var snapshotLists = map[string]ports.ListName{
+	domain.SnapshotMerged:  ports.ListMerged,
+	domain.SnapshotInclude: ports.ListInclude,
+	domain.SnapshotExclude: ports.ListExclude,
+}
+
+// SnapshotService takes numbered snapshots of each language's dictionary and
+// word lists, after every mutation (when onMutation is set) or on demand, and
+// can diff and roll back to them. Only the newest retention snapshots of each
+// language are kept; 0 keeps them all.
+type SnapshotService struct {
+	words      *WordService
+	store      ports.SnapshotStore
+	retention  int
+	onMutation bool
+
+	// mu keeps a snapshot from interleaving with a rollback
+	mu sync.Mutex
+
+	definitions definitionsCache
+}
+
+// definitionsCache keeps the last read of each language's base dictionary,
+// which rarely changes, so snapshots don't hash it again on every mutation
+type definitionsCache struct {
+	mu         sync.Mutex
+	byLanguage map[string]cachedDefinitions
+}
+
+// cachedDefinitions is a dictionary as read, with the recorded checksum and
+// the file size and modification time it was read under
+type cachedDefinitions struct {
+	checksum string
+	size     int64
+	modTime  int64
+	lines    []string
+}
+
+func NewSnapshotService(words *WordService, store ports.SnapshotStore, retention int, onMutation bool) *SnapshotService {
+	return &SnapshotService{words: words, store: store, retention: retention, onMutation: onMutation}
+}
+
+// WordsChanged snapshots the language after a mutation. Rollbacks take their
+// own snapshot.
+func (s *SnapshotService) WordsChanged(ctx context.Context, m domain.Mutation) {
+	if !s.onMutation || m.Operation == domain.OperationRollback {
+		return
+	}
+	pack, err := s.words.languages.Get(m.Language)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.create(ctx, pack, m.Operation, ""); err != nil {
+		// The mutation itself succeeded; a missed snapshot is only logged
+		slog.ErrorContext(ctx, "taking snapshot", "operation", m.Operation, "language", m.Language, "err", err)
+	}
+}
+
+// EnsureBaseline snapshots every language that has no snapshot yet, so the
+// first mutation can be rolled back
+func (s *SnapshotService) EnsureBaseline(ctx context.Context) error {
+	snaps, err := s.store.List(ctx)
+	if err != nil {
+		return err
+	}
+	have := make(map[string]bool)
+	for _, snap := range snaps {
+		have[snap.Language] = true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, name := range s.words.languages.Names() {
+		if have[name] {
+			continue
+		}
+		pack, err := s.words.languages.Get(name)
+		if err != nil {
+			return err
+		}
+		if _, err := s.create(ctx, pack, triggerBaseline, ""); err != nil {
+			return fmt.Errorf("baseline snapshot for %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Create takes a snapshot on demand
+func (s *SnapshotService) Create(ctx context.Context, req domain.CreateSnapshotRequest) (domain.Snapshot, error) {
+	pack, err := s.words.languages.Get(req.Language)
+	if err != nil {
+		return domain.Snapshot{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.create(ctx, pack, triggerManual, req.Reason)
+}
+
+// List returns the snapshots of a language, oldest first
+func (s *SnapshotService) List(ctx context.Context, lang string) ([]domain.Snapshot, error) {
+	pack, err := s.words.languages.Get(lang)
+	if err != nil {
+		return nil, err
+	}
+	snaps, err := s.store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := []domain.Snapshot{}
+	for _, snap := range snaps {
+		if snap.Language == pack.Name {
+			res = append(res, snap)
+		}
+	}
+	return res, nil
+}
+
+// Diff compares snapshot from with snapshot to, or with the current state
+// when to is 0. Both must belong to the same language.
+func (s *SnapshotService) Diff(ctx context.Context, from, to int) (domain.SnapshotDiff, error) {
+	fromSnap, err := s.store.Get(ctx, from)
+	if err != nil {
+		return domain.SnapshotDiff{}, err
+	}
+	pack, err := s.words.languages.Get(fromSnap.Language)
+	if err != nil {
+		return domain.SnapshotDiff{}, err
+	}
+	before, err := s.readSnapshot(ctx, fromSnap)
+	if err != nil {
+		return domain.SnapshotDiff{}, err
+	}
+
+	var after map[string][]string
+	if to == 0 {
+		after, err = s.current(ctx, pack)
+	} else {
+		var toSnap domain.Snapshot
+		if toSnap, err = s.store.Get(ctx, to); err != nil {
+			return domain.SnapshotDiff{}, err
+		}
+		if toSnap.Language != fromSnap.Language {
+			return domain.SnapshotDiff{}, fmt.Errorf("%w: snapshot %d is %s but snapshot %d is %s",
+				domain.ErrInvalidRequest, from, fromSnap.Language, to, toSnap.Language)
+		}
+		after, err = s.readSnapshot(ctx, toSnap)
+	}
+	if err != nil {
+		return domain.SnapshotDiff{}, err
+	}
+	return diffSnapshots(pack, from, to, before, after), nil
+}
+
+// Rollback writes the merged, include and exclude lists of a snapshot back,
+// then snapshots the restored state. The base dictionary is not touched.
+func (s *SnapshotService) Rollback(ctx context.Context, req domain.RollbackRequest) (domain.RollbackResponse, error) {
+	if err := s.words.checkWritable(); err != nil {
+		return domain.RollbackResponse{}, err
+	}
+	snap, err := s.store.Get(ctx, req.ID)
+	if err != nil {
+		return domain.RollbackResponse{}, err
+	}
+	pack, err := s.words.languages.Get(snap.Language)
+	if err != nil {
+		return domain.RollbackResponse{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	target, err := s.readSnapshot(ctx, snap)
+	if err != nil {
+		return domain.RollbackResponse{}, err
+	}
+	before, err := s.current(ctx, pack)
+	if err != nil {
+		return domain.RollbackResponse{}, err
+	}
+
+	// The base dictionary stays as it is now, whatever the snapshot held
+	target[domain.SnapshotDefinitions] = before[domain.SnapshotDefinitions]
+
+	reason := fmt.Sprintf("rollback to snapshot %d", snap.ID)
+	mutation := domain.Mutation{Operation: domain.OperationRollback, Language: pack.Name, Reason: reason}
+	for name, list := range snapshotLists {
+		ref := ports.ListRef{Language: pack.Name, Name: list}
+		if err := s.words.words.Replace(ctx, ref, target[name]); err != nil {
+			return domain.RollbackResponse{}, err
+		}
+		added, removed := diffLines(before[name], target[name])
+		mutation.Changes = append(mutation.Changes, domain.ListChange{List: string(list), Added: added, Removed: removed})
+	}
+	s.words.notify(ctx, mutation)
+
+	after, err := s.create(ctx, pack, domain.OperationRollback, reason)
+	if err != nil {
+		return domain.RollbackResponse{}, err
+	}
+	return domain.RollbackResponse{
+		RestoredFrom: snap.ID,
+		Diff:         diffSnapshots(pack, 0, after.ID, before, target),
+		Snapshot:     after,
+	}, nil
+}
+
+// create snapshots the current state of a language and applies retention.
+// Callers hold s.mu.
+func (s *SnapshotService) create(ctx context.Context, pack *language.Pack, trigger, reason string) (domain.Snapshot, error) {
+	lists, err := s.current(ctx, pack)
+	if err != nil {
+		return domain.Snapshot{}, err
+	}
+	snap, err := s.store.Save(ctx, domain.Snapshot{
+		Language:  pack.Name,
+		CreatedAt: time.Now().UTC(),
+		Trigger:   trigger,
+		Reason:    reason,
+	}, lists)
+	if err != nil {
+		return domain.Snapshot{}, err
+	}
+	if err := s.prune(ctx, pack.Name); err != nil {
+		slog.ErrorContext(ctx, "pruning snapshots", "language", pack.Name, "err", err)
+	}
+	return snap, nil
+}
+
+// prune deletes the oldest snapshots of a language beyond the retention limit
+func (s *SnapshotService) prune(ctx context.Context, lang string) error {
+	if s.retention <= 0 {
+		return nil
+	}
+	snaps, err := s.store.List(ctx)
+	if err != nil {
+		return err
+	}
+	var ids []int
+	for _, snap := range snaps {
+		if snap.Language == lang {
+			ids = append(ids, snap.ID)
+		}
+	}
+	for len(ids) > s.retention {
+		if err := s.store.Delete(ctx, ids[0]); err != nil {
+			return err
+		}
+		ids = ids[1:]
+	}
+	return nil
+}
+
+// current reads the base dictionary and word lists of a language as they are now
+func (s *SnapshotService) current(ctx context.Context, pack *language.Pack) (map[string][]string, error) {
+	lists := make(map[string][]string, len(snapshotLists)+1)
+	definitions, err := s.readDefinitions(pack)
+	if err != nil {
+		return nil, err
+	}
+	lists[domain.SnapshotDefinitions] = definitions
+	for name, list := range snapshotLists {
+		words, err := s.words.words.List(ctx, ports.ListRef{Language: pack.Name, Name: list})
+		if err != nil {
+			return nil, err
+		}
+		lists[name] = words
+	}
+	return lists, nil
+}
+
+// readDefinitions reads the base dictionary of a language, or nothing if it
+// is missing. It is only read (and checked against its checksum) again once
+// its recorded checksum, size or modification time changes.
+func (s *SnapshotService) readDefinitions(pack *language.Pack) ([]string, error) {
+	fh := s.words.fileHelper
+	info, err := os.Stat(fh.Path(pack.ResourceDir, pack.DictionaryFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	sum, err := fh.Checksum(pack.ResourceDir, pack.DictionaryFile)
+	if err != nil {
+		return nil, err
+	}
+
+	s.definitions.mu.Lock()
+	defer s.definitions.mu.Unlock()
+	cached, ok := s.definitions.byLanguage[pack.Name]
+	if ok && cached.checksum == sum && cached.size == info.Size() && cached.modTime == info.ModTime().UnixNano() {
+		return cached.lines, nil
+	}
+	lines, err := fh.ReadFileAsync(pack.ResourceDir, pack.DictionaryFile)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if s.definitions.byLanguage == nil {
+		s.definitions.byLanguage = make(map[string]cachedDefinitions)
+	}
+	s.definitions.byLanguage[pack.Name] = cachedDefinitions{checksum: sum, size: info.Size(), modTime: info.ModTime().UnixNano(), lines: lines}
+	return lines, nil
+}
+
+// readSnapshot loads every list of a snapshot
+func (s *SnapshotService) readSnapshot(ctx context.Context, snap domain.Snapshot) (map[string][]string, error) {
+	lists := make(map[string][]string, len(snap.Lists))
+	for name := range snap.Lists {
+		words, err := s.store.ReadList(ctx, snap, name)
+		if err != nil {
+			return nil, err
+		}
+		lists[name] = words
+	}
+	return lists, nil
+}
+
+// diffSnapshots compares each list line by line, and the composed dictionary
+// (definitions plus merged) word by word on the normalised key
+func diffSnapshots(pack *language.Pack, from, to int, before, after map[string][]string) domain.SnapshotDiff {
+	diff := domain.SnapshotDiff{From: from, To: to, Lists: make(map[string]domain.ListDiff)}
+	for _, name := range []string{domain.SnapshotInclude, domain.SnapshotExclude, domain.SnapshotMerged} {
+		added, removed := diffLines(before[name], after[name])
+		diff.Lists[name] = domain.ListDiff{Added: nonNil(added), Removed: nonNil(removed)}
+	}
+
+	beforeWords := composedWords(pack, before)
+	afterWords := composedWords(pack, after)
+	var added, removed []string
+	for key, word := range afterWords {
+		if _, ok := beforeWords[key]; !ok {
+			added = append(added, word)
+		}
+	}
+	for key, word := range beforeWords {
+		if _, ok := afterWords[key]; !ok {
+			removed = append(removed, word)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	diff.Lists[domain.SnapshotDictionary] = domain.ListDiff{Added: nonNil(added), Removed: nonNil(removed)}
+	return diff
+}
+
+// composedWords returns key -> display word for definitions plus merged
+func composedWords(pack *language.Pack, lists map[string][]string) map[string]string {
+	normalizer := pack.Normalizer()
+	words := make(map[string]string)
+	lines := append(append([]string{}, lists[domain.SnapshotDefinitions]...), lists[domain.SnapshotMerged]...)
+	for _, entry := range dictionary.ParseLines(lines) {
+		key := normalizer.Key(entry.Word)
+		if _, ok := words[key]; key != "" && !ok {
+			words[key] = normalizer.Clean(entry.Word)
+		}
+	}
+	return words
+}
+
+// diffLines returns the lines of after not in before, and of before not in after
+func diffLines(before, after []string) (added, removed []string) {
+	inBefore := make(map[string]bool, len(before))
+	for _, line := range before {
+		inBefore[line] = true
+	}
+	inAfter := make(map[string]bool, len(after))
+	for _, line := range after {
+		inAfter[line] = true
+		if !inBefore[line] {
+			added = append(added, line)
+		}
+	}
+	for _, line := range before {
+		if !inAfter[line] {
+			removed = append(removed, line)
+		}
+	}
+	return added, removed
+}
+
+func nonNil(words []string) []string {
+	if words == nil {
+		return []string{}
+	}
+	return words
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+
+	"service-matrix-go/internal/core/domain"
+	"service-matrix-go/internal/core/ports"
+	"service-matrix-go/internal/infrastructure/storage"
+)
+
+// snapshotService snapshots a fileService over "кот\nдом\n" into its data
+// directory, keeping retention snapshots per language
+func snapshotService(t *testing.T, retention int, onMutation bool) (*SnapshotService, *storage.FileHelper) {
+	t.Helper()
+	s, fh := fileService(t, "кот\nдом\n")
+	snaps := NewSnapshotService(s, storage.NewFileSnapshotStore(fh, filepath.Join("data", "snapshots")), retention, onMutation)
+	s.Observe(snaps)
+	if err := snaps.EnsureBaseline(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return snaps, fh
+}
+
+// snapshotIDs returns the IDs and triggers of a language's snapshots, oldest first
+func snapshotIDs(t *testing.T, snaps *SnapshotService, lang string) ([]int, []string) {
+	t.Helper()
+	list, err := snaps.List(context.Background(), lang)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []int
+	var triggers []string
+	for _, snap := range list {
+		ids = append(ids, snap.ID)
+		triggers = append(triggers, snap.Trigger)
+	}
+	return ids, triggers
+}
+
+func TestSnapshotAfterMutation(t *testing.T) {
+	snaps, _ := snapshotService(t, 0, true)
+	ctx := context.Background()
+	if _, err := snaps.words.UpdateWords(ctx, domain.UpdateWordsRequest{Words: []string{"лес"}, Include: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	ids, triggers := snapshotIDs(t, snaps, "ru")
+	if !slices.Equal(triggers, []string{triggerBaseline, domain.OperationAdd}) {
+		t.Fatalf("snapshot triggers %q, want the baseline then the add", triggers)
+	}
+	snap, err := snaps.store.Get(ctx, ids[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	lists, err := snaps.readSnapshot(ctx, snap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(lists[domain.SnapshotInclude], []string{"лес"}) || !slices.Equal(lists[domain.SnapshotDefinitions], []string{"кот", "дом"}) {
+		t.Errorf("snapshot after the add holds %q, want лес included over the dictionary", lists)
+	}
+}
+
+func TestSnapshotDiffWithCurrentState(t *testing.T) {
+	snaps, fh := snapshotService(t, 0, false)
+	ctx := context.Background()
+	if _, err := snaps.words.UpdateWords(ctx, domain.UpdateWordsRequest{Words: []string{"лес"}, Include: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := fh.WriteFileNewContents([]string{"ток\tэлектрический"}, "resources", "merged.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	ru, _ := snapshotIDs(t, snaps, "ru")
+	diff, err := snaps.Diff(ctx, ru[0], 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]domain.ListDiff{
+		domain.SnapshotInclude:    {Added: []string{"лес"}, Removed: []string{}},
+		domain.SnapshotExclude:    {Added: []string{}, Removed: []string{}},
+		domain.SnapshotMerged:     {Added: []string{"ток\tэлектрический"}, Removed: []string{}},
+		domain.SnapshotDictionary: {Added: []string{"ток"}, Removed: []string{}},
+	}
+	if diff.From != ru[0] || diff.To != 0 || !reflect.DeepEqual(diff.Lists, want) {
+		t.Errorf("Diff(%d, 0) = %+v, want %+v", ru[0], diff, want)
+	}
+
+	// Snapshots of different languages don't compare
+	en, _ := snapshotIDs(t, snaps, "en")
+	if _, err := snaps.Diff(ctx, ru[0], en[0]); !errors.Is(err, domain.ErrInvalidRequest) {
+		t.Errorf("diffing ru against en: %v, want ErrInvalidRequest", err)
+	}
+}
+
+func TestSnapshotRollback(t *testing.T) {
+	snaps, fh := snapshotService(t, 0, false)
+	ctx := context.Background()
+	s := snaps.words
+	write := func(lines []string, directory, file string) {
+		t.Helper()
+		if err := fh.WriteFileNewContents(lines, directory, file); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write([]string{"лес"}, "data", "include.txt")
+	write([]string{"кто"}, "data", "exclude.txt")
+	write([]string{"ток"}, "resources", "merged.txt")
+	snap, err := snaps.Create(ctx, domain.CreateSnapshotRequest{Reason: "before the cleanup"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	write([]string{"сад"}, "data", "include.txt")
+	write(nil, "data", "exclude.txt")
+	write([]string{"ток", "рот"}, "resources", "merged.txt")
+	write([]string{"кот", "дом", "мир"}, "resources", "definitions.txt")
+
+	res, err := snaps.Rollback(ctx, domain.RollbackRequest{ID: snap.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.RestoredFrom != snap.ID || res.Snapshot.Trigger != domain.OperationRollback {
+		t.Errorf("Rollback = %+v, want restored from %d and a rollback snapshot", res, snap.ID)
+	}
+	for list, want := range map[ports.ListName][]string{
+		ports.ListInclude: {"лес"},
+		ports.ListExclude: {"кто"},
+		ports.ListMerged:  {"ток"},
+	} {
+		if got, _ := s.words.List(ctx, ports.ListRef{Language: "ru", Name: list}); !slices.Equal(got, want) {
+			t.Errorf("%s list is %q after the rollback, want %q", list, got, want)
+		}
+	}
+
+	// The base dictionary is left as it is
+	if got, _ := fh.ReadFileAsync("resources", "definitions.txt"); !slices.Equal(got, []string{"кот", "дом", "мир"}) {
+		t.Errorf("definitions.txt is %q after the rollback, want it untouched", got)
+	}
+	if diff := res.Diff.Lists[domain.SnapshotDictionary]; !slices.Equal(diff.Added, []string{}) || !slices.Equal(diff.Removed, []string{"рот"}) {
+		t.Errorf("rollback dictionary diff %+v, want рот removed", diff)
+	}
+}
+
+func TestSnapshotRetention(t *testing.T) {
+	snaps, _ := snapshotService(t, 2, false)
+	ctx := context.Background()
+	en, _ := snapshotIDs(t, snaps, "en")
+
+	var created []int
+	for range 3 {
+		snap, err := snaps.Create(ctx, domain.CreateSnapshotRequest{Language: "ru"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		created = append(created, snap.ID)
+	}
+
+	// Only the oldest ru snapshots go; en keeps its own
+	if ru, _ := snapshotIDs(t, snaps, "ru"); !slices.Equal(ru, created[1:]) {
+		t.Errorf("ru snapshots %v, want the newest two of %v", ru, created)
+	}
+	if got, _ := snapshotIDs(t, snaps, "en"); !slices.Equal(got, en) {
+		t.Errorf("en snapshots %v, want %v untouched", got, en)
+	}
+}
+
+func TestSnapshotRereadsChangedDefinitions(t *testing.T) {
+	snaps, fh := snapshotService(t, 0, false)
+	ctx := context.Background()
+	pack, _ := snaps.words.languages.Get("ru")
+
+	if err := fh.WriteFileNewContents([]string{"кот", "дом", "мир"}, "resources", "definitions.txt"); err != nil {
+		t.Fatal(err)
+	}
+	lists, err := snaps.current(ctx, pack)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := lists[domain.SnapshotDefinitions]; !slices.Equal(got, []string{"кот", "дом", "мир"}) {
+		t.Errorf("definitions %q after a write, want the new contents", got)
+	}
+
+	// An edit that didn't go through a writer still fails the checksum
+	path := filepath.Join(fh.BaseDir, "resources", "definitions.txt")
+	if err := os.WriteFile(path, []byte("кот\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := snaps.current(ctx, pack); !errors.Is(err, storage.ErrCorruptFile) {
+		t.Errorf("snapshotting a hand-edited dictionary: %v, want ErrCorruptFile", err)
+	}
+}
@@ -1,315 +1,577 @@
 package services
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"service-matrix-go/internal/core/algorithm"
+	"service-matrix-go/internal/core/dictionary"
 	"service-matrix-go/internal/core/domain"
+	"service-matrix-go/internal/core/language"
+	"service-matrix-go/internal/core/ports"
+	"service-matrix-go/internal/infrastructure/dictindex"
 	"service-matrix-go/internal/infrastructure/storage"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 type WordService struct {
 	fileHelper *storage.FileHelper
+	words      ports.WordRepository
+	languages  *language.Registry
+	observers  []ports.MutationObserver
+	audit      *AuditService
+	// leader is set on a follower, whose lists only change by replication
+	leader string
+
+	dictionaries dictionaryCache
+	indexMode    dictindex.Mode
+	search       SearchSettings
+	metrics      *Metrics
 }
 
-func NewWordService(fh *storage.FileHelper) *WordService {
-	return &WordService{fileHelper: fh}
+// SearchSettings bound the boards Search accepts and fill in the options a
+// request leaves out. Zero values mean no limit and no default.
+type SearchSettings struct {
+	MaxRows   int
+	MaxCols   int
+	MaxWords  int
+	SortBy    string
+	MaxRarity string
 }
 
-// Search implements the word search logic based on WordSearchCommandHandler
-func (s *WordService) Search(req domain.SearchRequest) (map[string]map[int]map[string]string, error) {
-	definitionWords := []string{}
+func NewWordService(fh *storage.FileHelper, words ports.WordRepository, languages *language.Registry) *WordService {
+	return &WordService{fileHelper: fh, words: words, languages: languages}
+}
+
+// Observe registers o to be told about every word list mutation
+func (s *WordService) Observe(o ports.MutationObserver) {
+	s.observers = append(s.observers, o)
+}
+
+// UseSearchSettings sets the board limits and search defaults
+func (s *WordService) UseSearchSettings(settings SearchSettings) {
+	s.search = settings
+}
+
+// UseMetrics makes the service report searches, dictionary loads and
+// mutations to m
+func (s *WordService) UseMetrics(m *Metrics) {
+	s.metrics = m
+	s.Observe(m)
+}
+
+// UseAudit makes LookupWord report when and how each match was added
+func (s *WordService) UseAudit(a *AuditService) {
+	s.audit = a
+}
+
+// FollowLeader makes the include, exclude and merged lists read-only here:
+// they are replicated from leader, so local changes are refused with
+// ErrConflict rather than left to diverge from it
+func (s *WordService) FollowLeader(leader string) {
+	s.leader = leader
+}
+
+// checkWritable refuses a local change to the lists of a follower
+func (s *WordService) checkWritable() error {
+	if s.leader != "" {
+		return fmt.Errorf("%w: this instance follows %s; make changes on the leader", domain.ErrConflict, s.leader)
+	}
+	return nil
+}
+
+// notify passes a completed mutation on to the observers
+func (s *WordService) notify(ctx context.Context, m domain.Mutation) {
+	if m.IsEmpty() {
+		return
+	}
+	attrs := []any{"operation", m.Operation, "language", m.Language}
+	for _, c := range m.Changes {
+		attrs = append(attrs, slog.Group(c.List, "added", len(c.Added), "removed", len(c.Removed)))
+	}
+	slog.InfoContext(ctx, "word lists changed", attrs...)
+	for _, o := range s.observers {
+		o.WordsChanged(ctx, m)
+	}
+}
+
+// listSources names each list in LookupWord results, after the file it has always lived in
+var listSources = map[ports.ListName]string{
+	ports.ListMerged:  "merged.txt",
+	ports.ListInclude: "include.txt",
+	ports.ListExclude: "exclude.txt",
+}
+
+// wordList returns the include or exclude list ref of a pack
+func wordList(pack *language.Pack, include bool) ports.ListRef {
+	if include {
+		return ports.ListRef{Language: pack.Name, Name: ports.ListInclude}
+	}
+	return ports.ListRef{Language: pack.Name, Name: ports.ListExclude}
+}
 
-	dictionary, err := s.fileHelper.ReadFileAsync("resources", "definitions.txt")
+// Search implements the word search logic based on WordSearchCommandHandler
+// Results are ordered by length (or commonness with SortBy "frequency") and
+// capped at MaxWords.
+func (s *WordService) Search(ctx context.Context, req domain.SearchRequest) ([]domain.FoundWord, error) {
+	started := time.Now()
+	pack, err := s.languages.Get(req.Language)
 	if err != nil {
 		return nil, err
 	}
-	merged, err := s.fileHelper.ReadFileAsync("resources", "merged.txt")
-	if err == nil {
-		// Create a map to track existing words for efficient lookup
-		existingMap := make(map[string]bool)
-		for _, w := range dictionary {
-			existingMap[w] = true
-		}
+	normalizer := pack.Normalizer()
 
-		// Only append words that don't already exist
-		for _, w := range merged {
-			if !existingMap[w] {
-				dictionary = append(dictionary, w)
-			}
-		}
+	if err := s.validateSearch(pack, req); err != nil {
+		return nil, err
 	}
 
-	definitionWords = dictionary
-
-	// excludes, err := s.fileHelper.ReadFileAsync("data", "exclude.txt")
-	// if err == nil {
-	// 	excludeMap := make(map[string]bool)
-	// 	for _, line := range excludes {
-	// 		excludeMap[line] = true
-	// 	}
-
-	// 	// filter out excluded
-	// 	var filtered []string
-	// 	for _, w := range definitionWords {
-	// 		if len(w) > req.MaxLength || len(w) < req.MinLength {
-	// 			continue
-	// 		}
-	// 		if !excludeMap[w] {
-	// 			filtered = append(filtered, w)
-	// 		}
-	// 	}
-	// 	definitionWords = filtered
-	// }
+	if req.MaxWords <= 0 && s.search.MaxWords > 0 {
+		req.MaxWords = domain.FlexInt(s.search.MaxWords)
+	}
+	if req.SortBy == "" {
+		req.SortBy = s.search.SortBy
+	}
+	if req.MaxRarity == "" {
+		req.MaxRarity = s.search.MaxRarity
+	}
 
-	// Matrix conversion
-	rows := len(req.LettersMatrix)
-	if rows == 0 {
-		return nil, nil
+	maxRarity, err := domain.ParseTier(req.MaxRarity)
+	if err != nil {
+		return nil, err
+	}
+	switch req.SortBy {
+	case "", domain.SortByLength, domain.SortByFrequency:
+	default:
+		return nil, domain.InvalidField("sortBy", "unknown sortBy %q", req.SortBy)
+	}
+
+	dict, err := s.loadDictionary(ctx, pack)
+	if err != nil {
+		return nil, err
+	}
+	defer dict.release()
+
+	// Apply length, part-of-speech, tag and rarity filters before the (expensive) path finding
+	filtered := !req.Filters.IsEmpty() || maxRarity != domain.TierRare || req.MinLength > 0 || req.MaxLength > 0
+	keep := func(entry keyedEntry) bool {
+		if !req.Filters.Matches(entry.Entry) {
+			return false
+		}
+		if length := utf8.RuneCountInString(entry.Key); length < int(req.MinLength) || (req.MaxLength > 0 && length > int(req.MaxLength)) {
+			return false
+		}
+		return pack.TierFor(entry.Entry.FrequencyRank).Level() <= maxRarity.Level()
 	}
-	cols := len(req.LettersMatrix[0])
 
-	// Create matrix
+	// Matrix conversion
+	// Create matrix, keeping each row's length; masked cells stay empty so no
+	// letter can use them. cols is the widest row.
+	rows, cols := len(req.LettersMatrix), 0
 	lettersMatrix2D := make([][]string, rows)
-	for i := 0; i < rows; i++ {
-		lettersMatrix2D[i] = make([]string, cols)
-		for j := 0; j < cols; j++ {
-			if j < len(req.LettersMatrix[i]) {
-				lettersMatrix2D[i][j] = req.LettersMatrix[i][j]
+	for i, row := range req.LettersMatrix {
+		lettersMatrix2D[i] = make([]string, len(row))
+		for j, cell := range row {
+			if cell != domain.MaskedCell {
+				lettersMatrix2D[i][j] = normalizer.Key(cell)
 			}
 		}
+		cols = max(cols, len(row))
 	}
 
-	foundWordsList := make(map[string]map[int]map[string]string)
+	var foundWordsList []domain.FoundWord
 
-	for _, definitionWord := range definitionWords {
-		if _, exists := foundWordsList[definitionWord]; exists {
-			continue
+	err = dict.each(func(definitionWord keyedEntry) bool {
+		if filtered && !keep(definitionWord) {
+			return true
 		}
-
-		if !algorithm.IsAllLettersInMatrix(lettersMatrix2D, definitionWord) {
-			continue
+		if !algorithm.IsAllLettersInMatrix(lettersMatrix2D, definitionWord.Key) {
+			return true
 		}
 
-		searchHelper := algorithm.NewWordSearchHelper(definitionWord, lettersMatrix2D)
+		searchHelper := algorithm.NewWordSearchHelper(definitionWord.Key, lettersMatrix2D)
 		if searchHelper.Search() {
 			foundWord := searchHelper.GetFoundString()
-			if strings.EqualFold(definitionWord, foundWord) {
-				foundWordsList[foundWord] = searchHelper.GetFoundWord()
+			if definitionWord.Key == foundWord {
+				foundWordsList = append(foundWordsList, domain.FoundWord{
+					DictionaryEntry: definitionWord.Entry,
+					Tier:            pack.TierFor(definitionWord.Entry.FrequencyRank),
+					Path:            searchHelper.GetFoundWord(),
+					Score:           pack.Score(definitionWord.Entry.Word),
+				})
 			}
 		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Sort by length desc (or by frequency rank, unranked last) and take maxWords
+	sort.SliceStable(foundWordsList, func(i, j int) bool {
+		a, b := foundWordsList[i], foundWordsList[j]
+		if req.SortBy == domain.SortByFrequency && a.FrequencyRank != b.FrequencyRank {
+			if a.FrequencyRank == 0 || b.FrequencyRank == 0 {
+				return b.FrequencyRank == 0
+			}
+			return a.FrequencyRank < b.FrequencyRank
+		}
+		return utf8.RuneCountInString(a.Word) > utf8.RuneCountInString(b.Word)
+	})
+
+	s.metrics.searched(pack.Name, rows, cols, time.Since(started), len(foundWordsList))
+
+	if len(foundWordsList) > int(req.MaxWords) {
+		foundWordsList = foundWordsList[:max(int(req.MaxWords), 0)]
 	}
+	slog.DebugContext(ctx, "search finished",
+		"language", pack.Name,
+		"board", fmt.Sprintf("%dx%d", rows, cols),
+		"returned", len(foundWordsList),
+		"duration_ms", time.Since(started).Milliseconds(),
+	)
+	return foundWordsList, nil
+}
 
-	// Sort by length desc and take maxWords
-	type kv struct {
-		Key   string
-		Value map[int]map[string]string
+// validateSearch checks a search request before any work is done: the
+// board has a letter, is within the size limits and is made of the pack's
+// letters and masked cells, and the numeric options make sense. Rows may
+// differ in length.
+func (s *WordService) validateSearch(pack *language.Pack, req domain.SearchRequest) error {
+	rows := len(req.LettersMatrix)
+	if rows == 0 {
+		return domain.InvalidField("lettersMatrix", "lettersMatrix must have at least one row")
 	}
-	var ss []kv
-	for k, v := range foundWordsList {
-		ss = append(ss, kv{k, v})
+	if s.search.MaxRows > 0 && rows > s.search.MaxRows {
+		return domain.InvalidField("lettersMatrix", "board has %d rows, at most %d are allowed", rows, s.search.MaxRows)
 	}
-	sort.Slice(ss, func(i, j int) bool {
-		return len(ss[i].Key) > len(ss[j].Key)
-	})
 
-	topResults := make(map[string]map[int]map[string]string)
-	count := 0
-	for _, kv := range ss {
-		if count >= int(req.MaxWords) {
-			break
+	normalizer := pack.Normalizer()
+	letters := 0
+	for i, row := range req.LettersMatrix {
+		if s.search.MaxCols > 0 && len(row) > s.search.MaxCols {
+			return domain.InvalidField(fmt.Sprintf("lettersMatrix[%d]", i), "board row %d has %d cells, at most %d are allowed", i, len(row), s.search.MaxCols)
+		}
+		for j, cell := range row {
+			if cell == domain.MaskedCell {
+				continue
+			}
+			field := fmt.Sprintf("lettersMatrix[%d][%d]", i, j)
+			key := normalizer.Key(cell)
+			if key == "" {
+				return domain.InvalidField(field, "cell %d,%d is empty; mask it with %q", i, j, domain.MaskedCell)
+			}
+			letters++
+			if pack.Alphabet == "" {
+				continue
+			}
+			for _, r := range key {
+				if !pack.InAlphabet(r) {
+					return domain.InvalidField(field, "cell %d,%d: %q is not a letter of %s", i, j, cell, pack.Name)
+				}
+			}
 		}
-		topResults[kv.Key] = kv.Value
-		count++
 	}
-	fmt.Printf("DEBUG: topResults count: %d\n", len(topResults))
-	return topResults, nil
+	if letters == 0 {
+		return domain.InvalidField("lettersMatrix", "board has no letters")
+	}
+
+	switch {
+	case req.MinLength < 0:
+		return domain.InvalidField("minLength", "minLength must not be negative")
+	case req.MaxLength < 0:
+		return domain.InvalidField("maxLength", "maxLength must not be negative")
+	case req.MaxLength > 0 && req.MinLength > req.MaxLength:
+		return domain.InvalidField("minLength", "minLength %d is greater than maxLength %d", req.MinLength, req.MaxLength)
+	case req.MaxWords < 0:
+		// 0, like leaving it out, leaves the default in place
+		return domain.InvalidField("maxWords", "maxWords must not be negative; 0 uses the default")
+	}
+	return nil
 }
 
-// UpdateWords implements UpdateWordsCommandHandler
-func (s *WordService) UpdateWords(req domain.UpdateWordsRequest) (int, error) {
-	filename := "exclude.txt"
-	if req.Include {
-		filename = "include.txt"
+// UpdateWords implements UpdateWordsCommandHandler: it adds words straight to
+// the include or exclude list. Player submissions go through ModerationService.
+func (s *WordService) UpdateWords(ctx context.Context, req domain.UpdateWordsRequest) (int, error) {
+	if err := s.checkWritable(); err != nil {
+		return 0, err
+	}
+	pack, err := s.languages.Get(req.Language)
+	if err != nil {
+		return 0, err
 	}
+	normalizer := pack.Normalizer()
 
-	existing, _ := s.fileHelper.ReadFileAsync("data", filename)
+	list := wordList(pack, req.Include)
+
+	existing, err := s.words.List(ctx, list)
+	if err != nil {
+		return 0, err
+	}
 	existingMap := make(map[string]bool)
 	for _, w := range existing {
-		existingMap[w] = true
+		existingMap[normalizer.Key(w)] = true
 	}
 
 	var newWords []string
-	count := 0
 	for _, w := range req.Words {
-		if !existingMap[w] {
-			newWords = append(newWords, w)
-			count++
+		key := normalizer.Key(w)
+		if key == "" || existingMap[key] {
+			continue
 		}
+		existingMap[key] = true
+		newWords = append(newWords, normalizer.Clean(w))
 	}
 
-	if len(newWords) > 0 {
-		err := s.fileHelper.WriteFileAppend(newWords, "data", filename)
-		if err != nil {
-			return 0, err
-		}
+	added, err := s.words.Add(ctx, list, newWords)
+	if err != nil {
+		return 0, err
 	}
-	return count, nil
+	s.notify(ctx, domain.Mutation{
+		Operation: domain.OperationAdd,
+		Language:  pack.Name,
+		Reason:    req.Reason,
+		Changes:   []domain.ListChange{{List: string(list.Name), Added: added}},
+	})
+	return len(added), nil
 }
 
 // GetList implements GetWordsQueryHandler
-func (s *WordService) GetList(include bool) ([]string, error) {
-	filename := "exclude.txt"
-	if include {
-		filename = "include.txt"
+func (s *WordService) GetList(ctx context.Context, lang string, include bool) ([]string, error) {
+	pack, err := s.languages.Get(lang)
+	if err != nil {
+		return nil, err
 	}
-	return s.fileHelper.ReadFileAsync("data", filename)
+
+	lines, err := s.words.List(ctx, wordList(pack, include))
+	if err != nil {
+		return nil, err
+	}
+	words := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if w := pack.Normalizer().Clean(line); w != "" {
+			words = append(words, w)
+		}
+	}
+	return words, nil
 }
 
-// MergeWords implements MergeWordsCommandHandler
-func (s *WordService) MergeWords() (domain.MergeResponse, error) {
-	// removedCounter := 0 // Unused in C# logic effectively as it's always 0
+// FindInList returns the include or exclude entry for word, as it is
+// stored, or ErrNotFound
+func (s *WordService) FindInList(ctx context.Context, lang string, include bool, word string) (domain.ListWordResponse, error) {
+	pack, err := s.languages.Get(lang)
+	if err != nil {
+		return domain.ListWordResponse{}, err
+	}
+	normalizer := pack.Normalizer()
+	key := normalizer.Key(word)
+	if key == "" {
+		return domain.ListWordResponse{}, domain.InvalidField("word", "word is required")
+	}
 
-	includes, err := s.fileHelper.ReadFileAsync("data", "include.txt")
+	list := wordList(pack, include)
+	lines, err := s.words.List(ctx, list)
 	if err != nil {
-		return domain.MergeResponse{}, err
+		return domain.ListWordResponse{}, err
 	}
+	for _, line := range lines {
+		if normalizer.Key(line) == key {
+			return domain.ListWordResponse{List: string(list.Name), Language: pack.Name, Word: normalizer.Clean(line)}, nil
+		}
+	}
+	return domain.ListWordResponse{}, fmt.Errorf("%w: %q is not in the %s list", domain.ErrNotFound, word, list.Name)
+}
 
-	dictionary, err := s.fileHelper.ReadFileAsync("resources", "definitions.txt")
-	if err == nil {
-		merged, _ := s.fileHelper.ReadFileAsync("resources", "merged.txt")
-		dictionary = append(dictionary, merged...)
+// PreviewMerge is the first phase of MergeWordsCommandHandler: it checks every
+// include against the dictionary, stores the candidates in the mergeable list
+// for review and reports why the others were rejected. Nothing is merged yet.
+func (s *WordService) PreviewMerge(ctx context.Context, lang string) (domain.MergePreviewResponse, error) {
+	if err := s.checkWritable(); err != nil {
+		return domain.MergePreviewResponse{}, err
+	}
+	pack, err := s.languages.Get(lang)
+	if err != nil {
+		return domain.MergePreviewResponse{}, err
 	}
 
-	// Create set for dictionary
-	dictMap := make(map[string]bool)
-	for _, w := range dictionary {
-		dictMap[w] = true
+	includes, err := s.words.List(ctx, wordList(pack, true))
+	if err != nil {
+		return domain.MergePreviewResponse{}, err
+	}
+	dict, err := s.loadDictionary(ctx, pack)
+	if err != nil {
+		return domain.MergePreviewResponse{}, err
 	}
+	defer dict.release()
 
-	var mergedList []string
-	var remainingIncludes []string
+	candidates, rejected := classifyMergeCandidates(pack, includes, dict.contains)
 
-	for _, include := range includes {
-		if len(include) < 4 || strings.Contains(include, "-") || strings.Contains(include, " ") {
-			// Skip and effectively remove from includes logic?
-			// In C#, it says: if (...) continue; -> so it's NOT added to mergedList.
-			// But later: includes = includes.Except(mergedList).ToList();
-			// So if it was skipped here, it remains in includes?
-			// C# logic:
-			// foreach include in includes:
-			//    if invalid continue
-			//    if !dict.Contains(include) -> add to mergedList
-			// includes = includes - mergedList
-			// save mergedList to "mergeable_definitions.txt"
-			// save includes to "include.txt"
+	err = s.words.Replace(ctx, ports.ListRef{Language: pack.Name, Name: ports.ListMergeable}, candidates)
+	if err != nil {
+		return domain.MergePreviewResponse{}, err
+	}
 
-			// So invalid words remain in includes.
-			remainingIncludes = append(remainingIncludes, include)
-			continue
-		}
+	return domain.MergePreviewResponse{Candidates: candidates, Rejected: rejected}, nil
+}
 
-		includeFormatted := strings.ToLower(strings.TrimSpace(include))
-		includeFormatted = strings.ReplaceAll(includeFormatted, "ё", "е")
+// MergeWords is the second phase: it appends the reviewed candidates from the
+// mergeable list (or the subset named in req.Words) to merged.txt, removes
+// them from include.txt and clears the mergeable list. Candidates are checked
+// again, since the dictionary may have changed since the preview.
+func (s *WordService) MergeWords(ctx context.Context, req domain.MergeCommitRequest) (domain.MergeResponse, error) {
+	if err := s.checkWritable(); err != nil {
+		return domain.MergeResponse{}, err
+	}
+	pack, err := s.languages.Get(req.Language)
+	if err != nil {
+		return domain.MergeResponse{}, err
+	}
+	normalizer := pack.Normalizer()
+	mergeableList := ports.ListRef{Language: pack.Name, Name: ports.ListMergeable}
+	includeList := wordList(pack, true)
 
-		if !dictMap[includeFormatted] {
-			mergedList = append(mergedList, includeFormatted)
-		} else {
-			remainingIncludes = append(remainingIncludes, include)
+	pending, err := s.words.List(ctx, mergeableList)
+	if err != nil {
+		return domain.MergeResponse{}, err
+	}
+	if len(req.Words) > 0 {
+		selected := make(map[string]bool)
+		for _, w := range req.Words {
+			selected[normalizer.Key(w)] = true
 		}
+		var subset []string
+		for _, w := range pending {
+			if selected[normalizer.Key(w)] {
+				subset = append(subset, w)
+			}
+		}
+		pending = subset
 	}
 
-	// Actually logic in C# for `includes = includes.Except(mergedList)`:
-	// If a word was added to mergedList, it is REMOVED from includes.
-	// If it was valid but already in dictionary, it was NOT added to mergedList. Does it remain in includes?
-	// C#: if !dictionary.Contains(...) -> added to mergedList.
-	// So if it IS in dictionary, it is NOT added to mergedList.
-	// Then Except(mergedList) removes only those added.
-	// So existing dictionary words stay in includes?
-	// Wait, that seems redundant. But following C# logic strictly:
-
-	// Re-evaluating C#:
-	// mergedList contains words from includes that are NOT in dictionary.
-	// includes (new) = includes (old) without words in mergedList.
-	// So words that moved to mergedList are removed from includes.
-	// Words that were ALREADY in dictionary stay in includes? That seems odd conceptually but I must follow code.
-	// Or maybe the intention was to clean includes?
-	// Let's stick to strict translation.
-
-	// In Go:
-	// mergedList collected correctly.
-	// We need to construct new includes list.
-	// It should contain everything from old includes EXCEPT what's in mergedList.
-
-	// Optimization:
-	// mergedSet := make(map[string]bool)
-	// for _, m := range mergedList { mergedSet[m] = true }
-	// newIncludes := []string{}
-	// for _, inc := range includes { if !mergedSet[inc] { newIncludes = append(newIncludes, inc) } }
-
-	// Writing files
-	if len(mergedList) > 0 {
-		err = s.fileHelper.WriteFileNewContents(mergedList, "data", "mergeable_definitions.txt")
-		if err != nil {
-			return domain.MergeResponse{}, err
-		}
+	dict, err := s.loadDictionary(ctx, pack)
+	if err != nil {
+		return domain.MergeResponse{}, err
 	}
+	defer dict.release()
+	toMerge, rejected := classifyMergeCandidates(pack, pending, dict.contains)
 
-	// We need to calculate the new includes list based on the logic
-	mergedSet := make(map[string]bool)
-	for _, m := range mergedList {
-		mergedSet[m] = true
+	added, err := s.words.Add(ctx, ports.ListRef{Language: pack.Name, Name: ports.ListMerged}, toMerge)
+	if err != nil {
+		return domain.MergeResponse{}, err
 	}
 
-	var finalIncludes []string
+	// Words that moved into merged.txt leave include.txt
+	mergedSet := make(map[string]bool)
+	for _, m := range added {
+		mergedSet[normalizer.Key(m)] = true
+	}
+	includes, err := s.words.List(ctx, includeList)
+	if err != nil {
+		return domain.MergeResponse{}, err
+	}
+	var mergedIncludes []string
 	for _, inc := range includes {
-		// C# logic does comparison potentially case-insensitive?
-		// C# Except is default equality (case-sensitive usually unless comparer provided).
-		// `mergedList` has lowercased formatted strings. `includes` has original strings.
-		// If `include` was "Foo", `includeFormatted`="foo". `mergedList` has "foo".
-		// `includes.Except` might NOT remove "Foo" if it checks against "foo"?
-		// C# List.Except uses Default comparer. String is case sensitive.
-		// So "Foo" would NOT be removed if "foo" is in mergedList?
-		// HOWEVER, `includeFormatted` was derived from `include`.
-		// If `include` was modified (trimmed, lowered), then `mergedList` content is different.
-		// So `Except` might functionally do nothing if valid words were mixed case?
-		// But likely `include.txt` is lowercase.
-		// I will implement case-insensitive removal to be safe or strictly follow C# (which might be buggy there).
-		// Let's assume strict C# behavior: Case Sensitive.
-
-		// Wait, if C# behaves that way, I should replicate it.
-		// If the user inputs "Word", it goes to mergedList as "word".
-		// simple "Word" != "word". So "Word" remains in includes.
-		// That effectively implementation DUPLICATES/Copies "word" to mergeable, and keeps "Word" in includes.
-		// Unless `include` strings are exact matches.
-
-		// Let's implement logical behavior: Remove if match.
-		// formatted := strings.ToLower(strings.TrimSpace(inc)) ...
-		if mergedSet[strings.ToLower(strings.TrimSpace(inc))] {
-			continue
+		if mergedSet[normalizer.Key(inc)] {
+			mergedIncludes = append(mergedIncludes, inc)
 		}
-		finalIncludes = append(finalIncludes, inc)
+	}
+	removed, err := s.words.Remove(ctx, includeList, mergedIncludes)
+	if err != nil {
+		return domain.MergeResponse{}, err
 	}
 
-	err = s.fileHelper.WriteFileNewContents(finalIncludes, "data", "include.txt")
+	// Whatever was reviewed is done with; rejected words stay in include.txt
+	_, err = s.words.Remove(ctx, mergeableList, pending)
 	if err != nil {
 		return domain.MergeResponse{}, err
 	}
 
-	return domain.MergeResponse{AddedCount: len(mergedList), RemovedCount: 0}, nil
+	s.notify(ctx, domain.Mutation{
+		Operation: domain.OperationMerge,
+		Language:  pack.Name,
+		Changes: []domain.ListChange{
+			{List: string(ports.ListMerged), Added: added},
+			{List: string(ports.ListInclude), Removed: removed},
+		},
+	})
+
+	if added == nil {
+		added = []string{}
+	}
+	return domain.MergeResponse{
+		AddedCount:   len(added),
+		RemovedCount: len(removed),
+		Added:        added,
+		Rejected:     rejected,
+	}, nil
+}
+
+// minMergeLength is the shortest word, in letters, that may be merged
+const minMergeLength = 4
+
+// classifyMergeCandidates splits words into merge candidates, in their
+// cleaned spelling, and rejections. As in the C# handler, words shorter than
+// four letters or containing a hyphen or space are never merged, nor are
+// words the dictionary already has.
+func classifyMergeCandidates(pack *language.Pack, words []string, inDictionary func(key string) bool) ([]string, []domain.MergeRejection) {
+	normalizer := pack.Normalizer()
+	candidates := []string{}
+	rejected := []domain.MergeRejection{}
+	seen := make(map[string]bool)
+
+	for _, w := range words {
+		word := normalizer.Clean(w)
+		key := normalizer.Key(w)
+
+		reason := ""
+		switch {
+		case key == "":
+			reason = domain.MergeReasonEmpty
+		case utf8.RuneCountInString(key) < minMergeLength:
+			reason = domain.MergeReasonTooShort
+		case strings.Contains(key, "-"):
+			reason = domain.MergeReasonHyphen
+		case strings.Contains(key, " "):
+			reason = domain.MergeReasonSpace
+		case inDictionary(key):
+			reason = domain.MergeReasonAlreadyPresent
+		case seen[key]:
+			reason = domain.MergeReasonDuplicate
+		}
+		if reason != "" {
+			rejected = append(rejected, domain.MergeRejection{Word: word, Reason: reason})
+			continue
+		}
+
+		seen[key] = true
+		// Keep the submitted spelling; later lookups compare on the key
+		candidates = append(candidates, word)
+	}
+	return candidates, rejected
 }
 
 // CleanMerge implements clean merge logic
-func (s *WordService) CleanMerge() (string, error) {
-	input, err := s.fileHelper.ReadFileAsync("resources", "merged.txt")
+func (s *WordService) CleanMerge(ctx context.Context, lang string) (string, error) {
+	pack, err := s.languages.Get(lang)
 	if err != nil {
 		return "", err
 	}
 
-	output := algorithm.CleanWords(input)
-	err = s.fileHelper.WriteFileNewContents(output, "data", "merged_cleaned.txt")
+	input, err := s.words.List(ctx, ports.ListRef{Language: pack.Name, Name: ports.ListMerged})
+	if err != nil {
+		return "", err
+	}
+
+	words := make([]string, 0, len(input))
+	for _, entry := range dictionary.ParseLines(input) {
+		words = append(words, pack.Normalizer().Clean(entry.Word))
+	}
+
+	output := algorithm.CleanWords(words)
+	err = s.words.Replace(ctx, ports.ListRef{Language: pack.Name, Name: ports.ListMergedCleaned}, output)
 	if err != nil {
 		return "", err
 	}
@@ -318,41 +580,76 @@ func (s *WordService) CleanMerge() (string, error) {
 }
 
 // LookupWord implements LookupWordQueryHandler
-func (s *WordService) LookupWord(word string, exactMatch bool) ([]domain.LookupResultResponseItem, error) {
+// With details set, matches from dictionary sources carry their definition and metadata.
+func (s *WordService) LookupWord(ctx context.Context, lang string, word string, exactMatch bool, details bool) ([]domain.LookupResultResponseItem, error) {
 	// Porting LookupWordQueryHandler
 	// I assume it looks in definitions, merged, include, exclude.
 
+	pack, err := s.languages.Get(lang)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(word) == "" {
+		return nil, domain.InvalidField("word", "word is required")
+	}
+	normalizer := pack.Normalizer()
+
 	var results []domain.LookupResultResponseItem
-	sources := map[string]string{
-		"definitions.txt": "resources",
-		"merged.txt":      "resources",
-		"include.txt":     "data",
-		"exclude.txt":     "data",
-	}
-
-	for file, dir := range sources {
-		lines, err := s.fileHelper.ReadFileAsync(dir, file)
-		if err == nil {
-			for i, line := range lines {
-				found := false
-				if exactMatch {
-					if strings.EqualFold(line, word) {
-						found = true
+	sources := make(map[string][]string)
+	sourceLists := make(map[string]ports.ListName)
+	if lines, err := s.fileHelper.ReadFileAsync(pack.ResourceDir, pack.DictionaryFile); err == nil {
+		sources[pack.DictionaryFile] = lines
+	}
+	for name, source := range listSources {
+		if lines, err := s.words.List(ctx, ports.ListRef{Language: pack.Name, Name: name}); err == nil {
+			sources[source] = lines
+			sourceLists[source] = name
+		}
+	}
+
+	wordKey := normalizer.Key(word)
+	for file, lines := range sources {
+		for i, line := range lines {
+			entry, ok := dictionary.ParseLine(line)
+			if !ok {
+				continue
+			}
+			lineKey := normalizer.Key(entry.Word)
+			found := false
+			if exactMatch {
+				if lineKey == wordKey {
+					found = true
+				}
+			} else {
+				if strings.Contains(lineKey, wordKey) {
+					found = true
+				}
+			}
+
+			if found {
+				item := domain.LookupResultResponseItem{
+					Word:   normalizer.Clean(entry.Word),
+					Found:  true,
+					Source: file,
+					Line:   i + 1,
+				}
+				if list, ok := sourceLists[file]; ok && s.audit != nil {
+					origin, ok, err := s.audit.Origin(ctx, pack, string(list), lineKey)
+					if err != nil {
+						return nil, err
 					}
-				} else {
-					if strings.Contains(strings.ToLower(line), strings.ToLower(word)) {
-						found = true
+					if ok {
+						item.Timestamp = origin.Time.Format(time.RFC3339)
+						item.Operation = origin.Operation
 					}
 				}
-
-				if found {
-					results = append(results, domain.LookupResultResponseItem{
-						Word:   line,
-						Found:  true,
-						Source: file,
-						Line:   i + 1,
-					})
+				if details {
+					item.Definition = entry.Definition
+					item.PartOfSpeech = entry.PartOfSpeech
+					item.FrequencyRank = entry.FrequencyRank
+					item.Tags = entry.Tags
 				}
+				results = append(results, item)
 			}
 		}
 	}
@@ -0,0 +1,213 @@
+package dictindex
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// A DAWG (directed acyclic word graph) is a trie whose equal suffixes are
+// shared, so the 160k dictionary keys fit in a few hundred kilobytes.
+//
+// Serialised layout, little endian:
+//
+//	uint32 node count | uint32 edge count | uint32 root node
+//	nodes: uint32 first edge | uint32 edge count (top bit: terminal)
+//	edges: uint32 label rune | uint32 target node
+//
+// Each node's edges are contiguous and sorted by label. The encoded form is
+// read in place, so a memory-mapped index is never copied.
+
+const (
+	dawgHeaderSize = 12
+	dawgNodeSize   = 8
+	dawgEdgeSize   = 8
+	terminalBit    = 1 << 31
+)
+
+// dawg reads an encoded DAWG in place
+type dawg struct {
+	nodes []byte
+	edges []byte
+	root  uint32
+}
+
+func openDAWG(data []byte) (*dawg, error) {
+	if len(data) < dawgHeaderSize {
+		return nil, fmt.Errorf("dawg section is %d bytes", len(data))
+	}
+	nodeCount := binary.LittleEndian.Uint32(data[0:])
+	edgeCount := binary.LittleEndian.Uint32(data[4:])
+	root := binary.LittleEndian.Uint32(data[8:])
+	want := dawgHeaderSize + int(nodeCount)*dawgNodeSize + int(edgeCount)*dawgEdgeSize
+	if len(data) != want || root >= nodeCount {
+		return nil, fmt.Errorf("dawg section has %d bytes for %d nodes and %d edges", len(data), nodeCount, edgeCount)
+	}
+	nodesEnd := dawgHeaderSize + int(nodeCount)*dawgNodeSize
+	return &dawg{nodes: data[dawgHeaderSize:nodesEnd], edges: data[nodesEnd:], root: root}, nil
+}
+
+// Contains reports whether key is one of the words
+func (d *dawg) Contains(key string) bool {
+	node := d.root
+	for _, r := range key {
+		next, ok := d.child(node, r)
+		if !ok {
+			return false
+		}
+		node = next
+	}
+	_, _, terminal := d.node(node)
+	return terminal
+}
+
+func (d *dawg) node(n uint32) (first, count uint32, terminal bool) {
+	off := int(n) * dawgNodeSize
+	first = binary.LittleEndian.Uint32(d.nodes[off:])
+	info := binary.LittleEndian.Uint32(d.nodes[off+4:])
+	return first, info &^ terminalBit, info&terminalBit != 0
+}
+
+func (d *dawg) child(n uint32, r rune) (uint32, bool) {
+	first, count, _ := d.node(n)
+	lo, hi := int(first), int(first+count)
+	for lo < hi {
+		mid := (lo + hi) / 2
+		label := rune(binary.LittleEndian.Uint32(d.edges[mid*dawgEdgeSize:]))
+		switch {
+		case label == r:
+			return binary.LittleEndian.Uint32(d.edges[mid*dawgEdgeSize+4:]), true
+		case label < r:
+			lo = mid + 1
+		default:
+			hi = mid
+		}
+	}
+	return 0, false
+}
+
+// dawgBuilder builds a minimal DAWG from sorted keys in one pass, merging
+// each finished suffix with an equal one already seen (Daciuk et al.)
+type dawgBuilder struct {
+	root      *buildNode
+	register  map[string]uint32
+	unchecked []buildStep
+	prev      []rune
+	prevKey   string
+
+	nodes []byte
+	edges []byte
+}
+
+type buildNode struct {
+	terminal bool
+	labels   []rune
+	children []*buildNode
+	id       uint32
+}
+
+// buildStep is an edge on the path of the last key not yet registered
+type buildStep struct {
+	parent *buildNode
+	child  *buildNode
+}
+
+func newDAWGBuilder() *dawgBuilder {
+	return &dawgBuilder{root: &buildNode{}, register: make(map[string]uint32)}
+}
+
+// Insert adds key, which must sort after every key inserted before it
+func (b *dawgBuilder) Insert(key string) error {
+	if key == "" {
+		return fmt.Errorf("dawg: empty key")
+	}
+	if b.prev != nil && key <= b.prevKey {
+		return fmt.Errorf("dawg: key %q is not after %q", key, b.prevKey)
+	}
+	runes := []rune(key)
+	common := 0
+	for common < len(runes) && common < len(b.prev) && runes[common] == b.prev[common] {
+		common++
+	}
+	b.minimize(common)
+
+	node := b.root
+	if len(b.unchecked) > 0 {
+		node = b.unchecked[len(b.unchecked)-1].child
+	}
+	for _, r := range runes[common:] {
+		child := &buildNode{}
+		node.labels = append(node.labels, r)
+		node.children = append(node.children, child)
+		b.unchecked = append(b.unchecked, buildStep{parent: node, child: child})
+		node = child
+	}
+	node.terminal = true
+	b.prev, b.prevKey = runes, key
+	return nil
+}
+
+// Finish registers the remaining nodes and returns the encoded DAWG
+func (b *dawgBuilder) Finish() []byte {
+	b.minimize(0)
+	root := b.add(b.root)
+
+	out := make([]byte, dawgHeaderSize, dawgHeaderSize+len(b.nodes)+len(b.edges))
+	binary.LittleEndian.PutUint32(out[0:], uint32(len(b.nodes)/dawgNodeSize))
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(b.edges)/dawgEdgeSize))
+	binary.LittleEndian.PutUint32(out[8:], root)
+	out = append(out, b.nodes...)
+	return append(out, b.edges...)
+}
+
+// minimize registers the unchecked path below depth, replacing each node by
+// an equal registered one where there is one
+func (b *dawgBuilder) minimize(depth int) {
+	for i := len(b.unchecked) - 1; i >= depth; i-- {
+		step := b.unchecked[i]
+		sig := signature(step.child)
+		id, ok := b.register[sig]
+		if !ok {
+			id = b.add(step.child)
+			b.register[sig] = id
+		}
+		// The child is always the parent's newest edge; only its ID is
+		// needed from now on, so let the rest of the subtree go
+		step.parent.children[len(step.parent.children)-1] = &buildNode{id: id}
+	}
+	b.unchecked = b.unchecked[:depth]
+}
+
+// add appends node to the encoded nodes; its children are already encoded
+func (b *dawgBuilder) add(node *buildNode) uint32 {
+	id := uint32(len(b.nodes) / dawgNodeSize)
+	first := uint32(len(b.edges) / dawgEdgeSize)
+	for i, child := range node.children {
+		b.edges = binary.LittleEndian.AppendUint32(b.edges, uint32(node.labels[i]))
+		b.edges = binary.LittleEndian.AppendUint32(b.edges, child.id)
+	}
+	info := uint32(len(node.children))
+	if node.terminal {
+		info |= terminalBit
+	}
+	b.nodes = binary.LittleEndian.AppendUint32(b.nodes, first)
+	b.nodes = binary.LittleEndian.AppendUint32(b.nodes, info)
+	return id
+}
+
+// signature identifies a node by its terminal flag and outgoing edges
+func signature(node *buildNode) string {
+	var sb strings.Builder
+	if node.terminal {
+		sb.WriteByte('1')
+	} else {
+		sb.WriteByte('0')
+	}
+	var buf [8]byte
+	for i, child := range node.children {
+		binary.LittleEndian.PutUint32(buf[0:], uint32(node.labels[i]))
+		binary.LittleEndian.PutUint32(buf[4:], child.id)
+		sb.Write(buf[:])
+	}
+	return sb.String()
+}
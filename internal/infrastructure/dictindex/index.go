@@ -0,0 +1,383 @@
+// Package dictindex stores a compiled dictionary in a binary file that loads
+// without parsing or normalising the text sources.
+//
+// File layout, little endian:
+//
+//	"SMDX" | uint32 format version
+//	uint32 length | metadata JSON
+//	uint32 length | DAWG of the entry keys
+//	uint32 length | entries
+//	SHA-256 of everything above
+//
+// Entries are in dictionary order, each a key, word, definition and part of
+// speech (uvarint length + bytes), a uvarint frequency rank and a uvarint
+// tag count followed by the tags. A word equal to its key is stored empty.
+package dictindex
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"time"
+
+	"service-matrix-go/internal/core/domain"
+	"service-matrix-go/internal/core/normalization"
+	"service-matrix-go/internal/infrastructure/storage"
+)
+
+// FormatVersion is bumped whenever the layout changes; older files are
+// treated as stale
+const FormatVersion = 1
+
+var magic = []byte("SMDX")
+
+// ErrCorruptIndex is returned for files that fail their checks on open
+var ErrCorruptIndex = errors.New("corrupt dictionary index")
+
+// Mode says how the server reads index files
+type Mode string
+
+const (
+	// ModeMmap maps the file into memory (where supported)
+	ModeMmap Mode = "mmap"
+	// ModeRead reads the whole file
+	ModeRead Mode = "read"
+	// ModeOff ignores index files and always parses the text sources
+	ModeOff Mode = "off"
+)
+
+// ParseMode parses a Mode; empty means ModeMmap
+func ParseMode(s string) (Mode, error) {
+	switch Mode(s) {
+	case "":
+		return ModeMmap, nil
+	case ModeMmap, ModeRead, ModeOff:
+		return Mode(s), nil
+	}
+	return "", fmt.Errorf("unknown dictionary index mode %q", s)
+}
+
+// Source fingerprints one input of the compiled dictionary. Files are
+// recorded by size and modification time, word lists by content hash.
+type Source struct {
+	Size    int64  `json:"size,omitempty"`
+	ModTime int64  `json:"modTime,omitempty"`
+	SHA256  string `json:"sha256,omitempty"`
+	Count   int    `json:"count,omitempty"`
+}
+
+// Metadata describes what an index was compiled from
+type Metadata struct {
+	FormatVersion int                   `json:"formatVersion"`
+	Language      string                `json:"language"`
+	BuiltAt       time.Time             `json:"builtAt"`
+	Entries       int                   `json:"entries"`
+	Normalization normalization.Options `json:"normalization"`
+	Sources       map[string]Source     `json:"sources"`
+	// Counts is how many entries each source contributed
+	Counts map[string]int `json:"counts,omitempty"`
+}
+
+// Matches reports whether an index built as meta is still current for the
+// given language, options and sources
+func (meta Metadata) Matches(lang string, opts normalization.Options, sources map[string]Source) bool {
+	if meta.FormatVersion != FormatVersion || meta.Language != lang || meta.Normalization != opts {
+		return false
+	}
+	if len(meta.Sources) != len(sources) {
+		return false
+	}
+	for name, src := range sources {
+		if meta.Sources[name] != src {
+			return false
+		}
+	}
+	return true
+}
+
+// Entry is a dictionary entry with its normalised key
+type Entry struct {
+	Key   string
+	Entry domain.DictionaryEntry
+}
+
+// Write compiles entries into an index file at path, replacing any old one
+// atomically. Keys must be unique.
+func Write(path string, meta Metadata, entries []Entry) error {
+	meta.FormatVersion = FormatVersion
+	meta.Entries = len(entries)
+
+	keys := make([]string, len(entries))
+	for i, e := range entries {
+		keys[i] = e.Key
+	}
+	sort.Strings(keys)
+	builder := newDAWGBuilder()
+	for _, key := range keys {
+		if err := builder.Insert(key); err != nil {
+			return err
+		}
+	}
+
+	metaJSON, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	buf.Write(magic)
+	buf.Write(binary.LittleEndian.AppendUint32(nil, FormatVersion))
+	writeSection(&buf, metaJSON)
+	writeSection(&buf, builder.Finish())
+	writeSection(&buf, encodeEntries(entries))
+	sum := sha256.Sum256(buf.Bytes())
+	buf.Write(sum[:])
+
+	return storage.WriteAtomic(path, buf.Bytes())
+}
+
+// Index is an open index file
+type Index struct {
+	meta    Metadata
+	keys    *dawg
+	entries []byte
+	release func() error
+}
+
+// ReadMetadata returns the metadata of the index at path without checking
+// the rest of the file
+func ReadMetadata(path string) (Metadata, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Metadata{}, err
+	}
+	meta, _, err := parseHeader(data)
+	return meta, err
+}
+
+// Open opens the index at path, mapping it into memory with ModeMmap, and
+// verifies its checksum. The index must be closed when no longer used; an
+// index dropped without Close is released by the garbage collector.
+func Open(path string, mode Mode) (*Index, error) {
+	var data []byte
+	release := func() error { return nil }
+	var err error
+	if mode == ModeMmap {
+		data, release, err = mapFile(path)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ix, err := parse(data)
+	if err != nil {
+		release()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	ix.release = release
+	runtime.SetFinalizer(ix, (*Index).Close)
+	return ix, nil
+}
+
+// Metadata returns what the index was compiled from
+func (ix *Index) Metadata() Metadata {
+	return ix.meta
+}
+
+// Contains reports whether key is in the dictionary
+func (ix *Index) Contains(key string) bool {
+	ok := ix.keys.Contains(key)
+	// The DAWG lives in the mapping, which must outlive the lookup
+	runtime.KeepAlive(ix)
+	return ok
+}
+
+// Len returns the number of entries
+func (ix *Index) Len() int {
+	return ix.meta.Entries
+}
+
+// Each decodes the entries one at a time, in dictionary order, and calls fn
+// with each until it returns false. Only the entry being visited is on the
+// heap; the rest stay in the file mapping.
+func (ix *Index) Each(fn func(Entry) bool) error {
+	err := decodeEntries(ix.entries, ix.meta.Entries, fn)
+	runtime.KeepAlive(ix)
+	return err
+}
+
+// Close releases the file mapping
+func (ix *Index) Close() error {
+	runtime.SetFinalizer(ix, nil)
+	release := ix.release
+	ix.release = func() error { return nil }
+	return release()
+}
+
+func parse(data []byte) (*Index, error) {
+	if len(data) < sha256.Size {
+		return nil, fmt.Errorf("%w: file is %d bytes", ErrCorruptIndex, len(data))
+	}
+	body := data[:len(data)-sha256.Size]
+	if sum := sha256.Sum256(body); !bytes.Equal(sum[:], data[len(body):]) {
+		return nil, fmt.Errorf("%w: checksum mismatch", ErrCorruptIndex)
+	}
+
+	meta, rest, err := parseHeader(body)
+	if err != nil {
+		return nil, err
+	}
+	if meta.FormatVersion != FormatVersion {
+		return nil, fmt.Errorf("format version %d, this build reads %d", meta.FormatVersion, FormatVersion)
+	}
+	dawgData, rest, err := readSection(rest)
+	if err != nil {
+		return nil, err
+	}
+	entries, rest, err := readSection(rest)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 {
+		return nil, fmt.Errorf("%w: %d trailing bytes", ErrCorruptIndex, len(rest))
+	}
+	keys, err := openDAWG(dawgData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCorruptIndex, err)
+	}
+	return &Index{meta: meta, keys: keys, entries: entries}, nil
+}
+
+// parseHeader checks the magic and version and decodes the metadata
+func parseHeader(data []byte) (Metadata, []byte, error) {
+	if len(data) < len(magic)+4 || !bytes.Equal(data[:len(magic)], magic) {
+		return Metadata{}, nil, fmt.Errorf("%w: not a dictionary index", ErrCorruptIndex)
+	}
+	if v := binary.LittleEndian.Uint32(data[len(magic):]); v != FormatVersion {
+		// Still report the metadata shape we know, so callers see it as stale
+		return Metadata{FormatVersion: int(v)}, nil, nil
+	}
+	metaJSON, rest, err := readSection(data[len(magic)+4:])
+	if err != nil {
+		return Metadata{}, nil, err
+	}
+	var meta Metadata
+	if err := json.Unmarshal(metaJSON, &meta); err != nil {
+		return Metadata{}, nil, fmt.Errorf("%w: metadata: %v", ErrCorruptIndex, err)
+	}
+	return meta, rest, nil
+}
+
+func writeSection(buf *bytes.Buffer, data []byte) {
+	buf.Write(binary.LittleEndian.AppendUint32(nil, uint32(len(data))))
+	buf.Write(data)
+}
+
+func readSection(data []byte) (section, rest []byte, err error) {
+	if len(data) < 4 {
+		return nil, nil, fmt.Errorf("%w: truncated section", ErrCorruptIndex)
+	}
+	n := binary.LittleEndian.Uint32(data)
+	if uint64(len(data)-4) < uint64(n) {
+		return nil, nil, fmt.Errorf("%w: section of %d bytes overruns the file", ErrCorruptIndex, n)
+	}
+	return data[4 : 4+n], data[4+n:], nil
+}
+
+func encodeEntries(entries []Entry) []byte {
+	var out []byte
+	putString := func(s string) {
+		out = binary.AppendUvarint(out, uint64(len(s)))
+		out = append(out, s...)
+	}
+	for _, e := range entries {
+		putString(e.Key)
+		if e.Entry.Word == e.Key {
+			putString("")
+		} else {
+			putString(e.Entry.Word)
+		}
+		putString(e.Entry.Definition)
+		putString(e.Entry.PartOfSpeech)
+		out = binary.AppendUvarint(out, uint64(e.Entry.FrequencyRank))
+		out = binary.AppendUvarint(out, uint64(len(e.Entry.Tags)))
+		for _, tag := range e.Entry.Tags {
+			putString(tag)
+		}
+	}
+	return out
+}
+
+// decodeEntries calls fn with each of the count entries in data until it
+// returns false. The whole section is checked only if fn visits every entry.
+func decodeEntries(data []byte, count int, fn func(Entry) bool) error {
+	errTruncated := fmt.Errorf("%w: truncated entries", ErrCorruptIndex)
+	uvarint := func() (uint64, error) {
+		v, n := binary.Uvarint(data)
+		if n <= 0 {
+			return 0, errTruncated
+		}
+		data = data[n:]
+		return v, nil
+	}
+	str := func() (string, error) {
+		n, err := uvarint()
+		if err != nil || uint64(len(data)) < n {
+			return "", errTruncated
+		}
+		s := string(data[:n])
+		data = data[n:]
+		return s, nil
+	}
+
+	for i := 0; i < count; i++ {
+		var e Entry
+		var err error
+		if e.Key, err = str(); err != nil {
+			return err
+		}
+		if e.Entry.Word, err = str(); err != nil {
+			return err
+		}
+		if e.Entry.Word == "" {
+			e.Entry.Word = e.Key
+		}
+		if e.Entry.Definition, err = str(); err != nil {
+			return err
+		}
+		if e.Entry.PartOfSpeech, err = str(); err != nil {
+			return err
+		}
+		rank, err := uvarint()
+		if err != nil {
+			return err
+		}
+		e.Entry.FrequencyRank = int(rank)
+		tags, err := uvarint()
+		if err != nil {
+			return err
+		}
+		for j := uint64(0); j < tags; j++ {
+			tag, err := str()
+			if err != nil {
+				return err
+			}
+			e.Entry.Tags = append(e.Entry.Tags, tag)
+		}
+		if !fn(e) {
+			return nil
+		}
+	}
+	if len(data) != 0 {
+		return fmt.Errorf("%w: %d bytes after the last entry", ErrCorruptIndex, len(data))
+	}
+	return nil
+}
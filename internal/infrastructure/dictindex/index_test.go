@@ -0,0 +1,213 @@
+package dictindex
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"service-matrix-go/internal/core/domain"
+	"service-matrix-go/internal/core/normalization"
+)
+
+var testEntries = []Entry{
+	{Key: "кот", Entry: domain.DictionaryEntry{Word: "кот", Definition: "Домашнее животное.", PartOfSpeech: "noun", FrequencyRank: 120, Tags: []string{"animal"}}},
+	{Key: "котёл", Entry: domain.DictionaryEntry{Word: "Котёл", Definition: "Сосуд для варки."}},
+	{Key: "ёж", Entry: domain.DictionaryEntry{Word: "ёж", FrequencyRank: 5000, Tags: []string{"animal", "forest"}}},
+	{Key: "ко", Entry: domain.DictionaryEntry{Word: "ко"}},
+	{Key: "дом", Entry: domain.DictionaryEntry{Word: "дом", Definition: "Здание."}},
+}
+
+var testMeta = Metadata{
+	Language:      "ru",
+	BuiltAt:       time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	Normalization: normalization.DefaultOptions,
+	Sources: map[string]Source{
+		"dictionary": {Size: 1234, ModTime: 5678},
+		"merged":     {SHA256: "abc", Count: 1},
+	},
+	Counts: map[string]int{"dictionary": 4, "merged": 1},
+}
+
+func writeIndex(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "dictionary.idx")
+	if err := Write(path, testMeta, testEntries); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func openIndex(t *testing.T, path string, mode Mode) *Index {
+	t.Helper()
+	ix, err := Open(path, mode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ix.Close() })
+	return ix
+}
+
+func TestRoundTrip(t *testing.T) {
+	path := writeIndex(t)
+	for _, mode := range []Mode{ModeMmap, ModeRead} {
+		t.Run(string(mode), func(t *testing.T) {
+			ix := openIndex(t, path, mode)
+
+			meta := ix.Metadata()
+			if meta.FormatVersion != FormatVersion || meta.Entries != len(testEntries) || ix.Len() != len(testEntries) {
+				t.Errorf("metadata says version %d, %d entries; want %d, %d", meta.FormatVersion, meta.Entries, FormatVersion, len(testEntries))
+			}
+			if !meta.Matches("ru", normalization.DefaultOptions, testMeta.Sources) {
+				t.Errorf("metadata %+v doesn't match what it was written with", meta)
+			}
+			if !meta.BuiltAt.Equal(testMeta.BuiltAt) || !reflect.DeepEqual(meta.Counts, testMeta.Counts) {
+				t.Errorf("metadata %+v, want built at %v with counts %v", meta, testMeta.BuiltAt, testMeta.Counts)
+			}
+
+			var got []Entry
+			if err := ix.Each(func(e Entry) bool { got = append(got, e); return true }); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, testEntries) {
+				t.Errorf("entries differ:\ngot  %+v\nwant %+v", got, testEntries)
+			}
+		})
+	}
+}
+
+func TestEachStops(t *testing.T) {
+	ix := openIndex(t, writeIndex(t), ModeMmap)
+	var keys []string
+	err := ix.Each(func(e Entry) bool {
+		keys = append(keys, e.Key)
+		return len(keys) < 2
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"кот", "котёл"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("visited %q, want %q", keys, want)
+	}
+}
+
+func TestContains(t *testing.T) {
+	ix := openIndex(t, writeIndex(t), ModeMmap)
+	tests := map[string]bool{
+		"кот":    true,
+		"котёл":  true,
+		"ко":     true,
+		"ёж":     true,
+		"дом":    true,
+		"":       false,
+		"к":      false, // a prefix of keys, not a key
+		"котё":   false,
+		"коты":   false,
+		"котёлы": false,
+		"Кот":    false, // keys are looked up as normalised
+		"еж":     false,
+		"house":  false,
+	}
+	for key, want := range tests {
+		if got := ix.Contains(key); got != want {
+			t.Errorf("Contains(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestMatchesDetectsChanges(t *testing.T) {
+	meta := openIndex(t, writeIndex(t), ModeRead).Metadata()
+	changed := map[string]Source{"dictionary": {Size: 1235, ModTime: 5678}, "merged": {SHA256: "abc", Count: 1}}
+	opts := normalization.DefaultOptions
+	opts.FoldYo = !opts.FoldYo
+
+	if meta.Matches("en", normalization.DefaultOptions, testMeta.Sources) {
+		t.Error("matches another language")
+	}
+	if meta.Matches("ru", opts, testMeta.Sources) {
+		t.Error("matches other normalisation options")
+	}
+	if meta.Matches("ru", normalization.DefaultOptions, changed) {
+		t.Error("matches a changed dictionary file")
+	}
+	if meta.Matches("ru", normalization.DefaultOptions, map[string]Source{"dictionary": testMeta.Sources["dictionary"]}) {
+		t.Error("matches without the merged list")
+	}
+}
+
+// reseal recomputes the trailing checksum after data was changed
+func reseal(data []byte) []byte {
+	body := data[:len(data)-sha256.Size]
+	sum := sha256.Sum256(body)
+	return append(body, sum[:]...)
+}
+
+func TestCorruptIndex(t *testing.T) {
+	tests := []struct {
+		name    string
+		damage  func(data []byte) []byte
+		corrupt bool
+	}{
+		{"empty", func([]byte) []byte { return nil }, true},
+		{"shorter than a checksum", func(data []byte) []byte { return data[:10] }, true},
+		{"truncated", func(data []byte) []byte { return data[:len(data)-40] }, true},
+		{"flipped byte", func(data []byte) []byte { data[len(data)/2] ^= 1; return data }, true},
+		{"bad magic", func(data []byte) []byte { copy(data, "XXXX"); return reseal(data) }, true},
+		{"metadata overruns the file", func(data []byte) []byte {
+			binary.LittleEndian.PutUint32(data[8:], 1<<30)
+			return reseal(data)
+		}, true},
+		{"metadata not JSON", func(data []byte) []byte { data[12] = '!'; return reseal(data) }, true},
+		{"newer format", func(data []byte) []byte {
+			binary.LittleEndian.PutUint32(data[4:], FormatVersion+1)
+			return reseal(data)
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeIndex(t)
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, tt.damage(data), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, mode := range []Mode{ModeMmap, ModeRead} {
+				ix, err := Open(path, mode)
+				if err == nil {
+					ix.Close()
+					t.Fatalf("%s: Open succeeded", mode)
+				}
+				if tt.corrupt != errors.Is(err, ErrCorruptIndex) {
+					t.Errorf("%s: Open: %v, want corrupt: %v", mode, err, tt.corrupt)
+				}
+			}
+		})
+	}
+}
+
+func TestReadMetadataOfANewerFormat(t *testing.T) {
+	path := writeIndex(t)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	binary.LittleEndian.PutUint32(data[4:], FormatVersion+1)
+	if err := os.WriteFile(path, reseal(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	meta, err := ReadMetadata(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.FormatVersion != FormatVersion+1 || meta.Matches("ru", normalization.DefaultOptions, testMeta.Sources) {
+		t.Errorf("metadata %+v should read as a stale newer format", meta)
+	}
+}
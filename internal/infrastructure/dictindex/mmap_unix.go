@@ -0,0 +1,36 @@
+//go:build unix
+
+package dictindex
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// mapFile maps path read-only. Index files are only ever replaced by
+// rename, so the mapping never sees a file change under it.
+func mapFile(path string) ([]byte, func() error, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+	size := info.Size()
+	if size == 0 {
+		return nil, nil, fmt.Errorf("%w: %s is empty", ErrCorruptIndex, path)
+	}
+	if int64(int(size)) != size {
+		return nil, nil, fmt.Errorf("%s is too large to map", path)
+	}
+	data, err := syscall.Mmap(int(file.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, func() error { return syscall.Munmap(data) }, nil
+}
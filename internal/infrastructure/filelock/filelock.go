@@ -0,0 +1,112 @@
+// Package filelock provides advisory locks on files shared between processes.
+// On unix systems the lock is a flock(2) on a dedicated lock file; elsewhere
+// it only guards against other users of this package in the same process.
+package filelock
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ErrLocked is returned by TryAcquire when someone else holds the lock
+var ErrLocked = errors.New("file is locked by another user")
+
+// Lock is a held lock; release it with Unlock
+type Lock struct {
+	path  string
+	file  *os.File
+	mutex *mutex
+}
+
+// mutex serialises lockers of one path within the process, since flock
+// locks are per open file description and would not block goroutines
+// sharing a file
+type mutex struct {
+	sync.Mutex
+	users int
+}
+
+// mutexes holds the mutex of every path someone is locking or waiting on;
+// a path's entry goes once its last user is done
+var (
+	mutexesMu sync.Mutex
+	mutexes   = make(map[string]*mutex)
+)
+
+func useMutex(path string) *mutex {
+	mutexesMu.Lock()
+	defer mutexesMu.Unlock()
+	m := mutexes[path]
+	if m == nil {
+		m = &mutex{}
+		mutexes[path] = m
+	}
+	m.users++
+	return m
+}
+
+func releaseMutex(path string, m *mutex) {
+	mutexesMu.Lock()
+	defer mutexesMu.Unlock()
+	if m.users--; m.users == 0 {
+		delete(mutexes, path)
+	}
+}
+
+// Acquire blocks until it holds an exclusive lock on path. The lock file is
+// created if needed and left in place afterwards.
+func Acquire(path string) (*Lock, error) {
+	m := useMutex(path)
+	m.Lock()
+	l, err := lock(path, m, lockFile)
+	if err != nil {
+		m.Unlock()
+		releaseMutex(path, m)
+	}
+	return l, err
+}
+
+// TryAcquire is Acquire without the wait: it fails with ErrLocked if the
+// lock is held, by this process or another
+func TryAcquire(path string) (*Lock, error) {
+	m := useMutex(path)
+	if !m.TryLock() {
+		releaseMutex(path, m)
+		return nil, ErrLocked
+	}
+	l, err := lock(path, m, tryLockFile)
+	if err != nil {
+		m.Unlock()
+		releaseMutex(path, m)
+	}
+	return l, err
+}
+
+// lock takes the file lock with the process mutex held
+func lock(path string, m *mutex, lockFn func(*os.File) error) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFn(file); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &Lock{path: path, file: file, mutex: m}, nil
+}
+
+// Unlock releases the lock
+func (l *Lock) Unlock() error {
+	err := unlockFile(l.file)
+	if cerr := l.file.Close(); err == nil {
+		err = cerr
+	}
+	l.mutex.Unlock()
+	releaseMutex(l.path, l.mutex)
+	return err
+}
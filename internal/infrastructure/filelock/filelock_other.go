@@ -0,0 +1,12 @@
+//go:build !unix
+
+package filelock
+
+import "os"
+
+// Without flock the in-process mutex is the only guard
+func lockFile(*os.File) error { return nil }
+
+func tryLockFile(*os.File) error { return nil }
+
+func unlockFile(*os.File) error { return nil }
@@ -0,0 +1,92 @@
+package filelock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func TestTryAcquire(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "list.txt.lock")
+
+	held, err := Acquire(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := TryAcquire(path); !errors.Is(err, ErrLocked) {
+		t.Fatalf("TryAcquire while held: %v, want ErrLocked", err)
+	}
+	held.Unlock()
+
+	lock, err := TryAcquire(path)
+	if err != nil {
+		t.Fatalf("TryAcquire once released: %v", err)
+	}
+	lock.Unlock()
+}
+
+func TestAcquireSerialises(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "list.txt.lock")
+	var wg sync.WaitGroup
+	inside, most := 0, 0
+	var mu sync.Mutex
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lock, err := Acquire(path)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			inside++
+			most = max(most, inside)
+			mu.Unlock()
+			runtime.Gosched()
+
+			mu.Lock()
+			inside--
+			mu.Unlock()
+			lock.Unlock()
+		}()
+	}
+	wg.Wait()
+	if most != 1 {
+		t.Errorf("%d holders at once, want 1", most)
+	}
+}
+
+func TestMutexesArePruned(t *testing.T) {
+	dir := t.TempDir()
+	for i := range 50 {
+		path := filepath.Join(dir, fmt.Sprintf("list%d.txt.lock", i))
+		lock, err := Acquire(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := TryAcquire(path); !errors.Is(err, ErrLocked) {
+			t.Fatalf("TryAcquire while held: %v, want ErrLocked", err)
+		}
+		lock.Unlock()
+	}
+
+	// A lock that can't be taken leaves nothing behind either
+	file := filepath.Join(dir, "list.txt")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Acquire(filepath.Join(file, "nested.lock")); err == nil {
+		t.Fatal("Acquire under a file succeeded")
+	}
+
+	mutexesMu.Lock()
+	defer mutexesMu.Unlock()
+	if len(mutexes) != 0 {
+		t.Errorf("%d mutexes kept after every lock was released", len(mutexes))
+	}
+}
@@ -0,0 +1,381 @@
+package kvstore
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"service-matrix-go/internal/infrastructure/filelock"
+)
+
+// Store is an embedded key-value store kept in a single append-only file.
+//
+// Every write is a batch record:
+//
+//	uint32 payload length | uint32 CRC-32 of payload | payload
+//
+// where the payload is a sequence of operations (op byte, uvarint key length,
+// key, and for puts uvarint value length and value). A batch is applied
+// all-or-nothing: a record torn by a crash mid-append, which can only be the
+// last one in the file, is dropped on open. A damaged record anywhere else
+// means the file itself is corrupt, and Open refuses it. The whole data set
+// is held in memory; the file is compacted once it holds mostly superseded
+// records.
+//
+// Only one process may have a store open at a time; a second Open fails
+// rather than risk both appending to the file.
+type Store struct {
+	mu      sync.RWMutex
+	path    string
+	file    *os.File
+	lock    *filelock.Lock
+	data    map[string][]byte
+	records int // operations in the file, live or superseded
+}
+
+const (
+	opPut    byte = 1
+	opDelete byte = 2
+
+	headerSize = 8
+
+	// compaction kicks in once the file holds this many operations and
+	// more than twice as many as there are live keys
+	compactMinRecords = 1024
+
+	// compactSuffix names the file a compaction writes before renaming it
+	// over the store
+	compactSuffix = ".compact"
+)
+
+// ErrCorrupt is returned by Open when a record before the end of the file
+// is damaged
+var ErrCorrupt = errors.New("kvstore: corrupt record")
+
+// Op is a single write in a batch
+type Op struct {
+	Key    string
+	Value  []byte
+	Delete bool
+}
+
+// Open opens or creates the store at path
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	lock, err := filelock.TryAcquire(path + ".lock")
+	if err != nil {
+		if errors.Is(err, filelock.ErrLocked) {
+			return nil, fmt.Errorf("kvstore: %s is open in another process", path)
+		}
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		lock.Unlock()
+		return nil, err
+	}
+
+	// A compaction that didn't reach its rename left the old file intact
+	if err := os.Remove(path + compactSuffix); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		lock.Unlock()
+		return nil, err
+	}
+
+	s := &Store{path: path, file: file, lock: lock, data: make(map[string][]byte)}
+	valid, size, err := s.replay()
+	if err != nil {
+		s.closeFiles()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if valid < size {
+		// Drop the torn tail so new records start on a clean boundary
+		slog.Warn("kvstore: dropping a torn record at the end of the file", "path", path, "offset", valid, "bytes", size-valid)
+		if err := file.Truncate(valid); err != nil {
+			s.closeFiles()
+			return nil, err
+		}
+		if err := file.Sync(); err != nil {
+			s.closeFiles()
+			return nil, err
+		}
+	}
+	if _, err := file.Seek(valid, io.SeekStart); err != nil {
+		s.closeFiles()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close closes the underlying file
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closeFiles()
+}
+
+func (s *Store) closeFiles() error {
+	err := s.file.Close()
+	if uerr := s.lock.Unlock(); err == nil {
+		err = uerr
+	}
+	return err
+}
+
+// Get returns the value of key
+func (s *Store) Get(key string) ([]byte, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	v, ok := s.data[key]
+	return v, ok
+}
+
+// Scan returns all keys with the given prefix in sorted order
+func (s *Store) Scan(prefix string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var keys []string
+	for k := range s.data {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Apply writes ops as one batch and syncs it to disk. Once the batch is on
+// disk it has been applied, so a compaction failing afterwards is only logged.
+func (s *Store) Apply(ops []Op) error {
+	if len(ops) == 0 {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.writeRecord(s.file, ops); err != nil {
+		return err
+	}
+	if err := s.file.Sync(); err != nil {
+		return err
+	}
+	s.apply(ops)
+	s.records += len(ops)
+
+	if s.records > compactMinRecords && s.records > 2*len(s.data) {
+		if err := s.compact(); err != nil {
+			slog.Error("kvstore: compaction failed", "path", s.path, "err", err)
+		}
+	}
+	return nil
+}
+
+func (s *Store) apply(ops []Op) {
+	for _, op := range ops {
+		if op.Delete {
+			delete(s.data, op.Key)
+		} else {
+			s.data[op.Key] = op.Value
+		}
+	}
+}
+
+// replay loads every intact record. It returns the offset after the last
+// one and the file size; anything between them is a torn last record. A bad
+// record with an intact record after it is ErrCorrupt, even when its damaged
+// length runs it to or past the end of the file.
+func (s *Store) replay() (valid int64, size int64, err error) {
+	info, err := s.file.Stat()
+	if err != nil {
+		return 0, 0, err
+	}
+	size = info.Size()
+
+	reader := bufio.NewReader(s.file)
+	header := make([]byte, headerSize)
+	for valid < size {
+		if size-valid < headerSize {
+			// A header cut short by the end of the file
+			return valid, size, nil
+		}
+		if _, err := io.ReadFull(reader, header); err != nil {
+			return 0, 0, err
+		}
+		length := int64(binary.BigEndian.Uint32(header[0:4]))
+		sum := binary.BigEndian.Uint32(header[4:8])
+		end := valid + headerSize + length
+		if end > size {
+			// A payload cut short by the end of the file, unless the length
+			// is what's damaged
+			rest, err := io.ReadAll(reader)
+			if err != nil {
+				return 0, 0, err
+			}
+			if recordFollows(rest) {
+				return 0, 0, fmt.Errorf("%w at offset %d", ErrCorrupt, valid)
+			}
+			return valid, size, nil
+		}
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(reader, payload); err != nil {
+			return 0, 0, err
+		}
+
+		ops, err := decodeOps(payload)
+		if err == nil && crc32.ChecksumIEEE(payload) != sum {
+			err = ErrCorrupt
+		}
+		if err != nil {
+			if end == size && !recordFollows(payload) {
+				// The last record, whose blocks the crash left unwritten
+				return valid, size, nil
+			}
+			return 0, 0, fmt.Errorf("%w at offset %d", ErrCorrupt, valid)
+		}
+		s.apply(ops)
+		s.records += len(ops)
+		valid = end
+	}
+	return valid, size, nil
+}
+
+// recordFollows reports whether an intact record starts anywhere in b. A
+// torn append leaves a prefix of one record, never a whole one after it.
+// Empty records are skipped: zeros left by a crash read as one.
+func recordFollows(b []byte) bool {
+	for p := 0; p+headerSize < len(b); p++ {
+		length := int(binary.BigEndian.Uint32(b[p : p+4]))
+		if length == 0 || length > len(b)-p-headerSize {
+			continue
+		}
+		payload := b[p+headerSize : p+headerSize+length]
+		if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(b[p+4:p+8]) {
+			continue
+		}
+		if _, err := decodeOps(payload); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// compact rewrites the live data into a fresh file and swaps it in
+func (s *Store) compact() error {
+	ops := make([]Op, 0, len(s.data))
+	for k, v := range s.data {
+		ops = append(ops, Op{Key: k, Value: v})
+	}
+
+	tmpPath := s.path + compactSuffix
+	tmp, err := os.OpenFile(tmpPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if err := s.writeRecord(tmp, ops); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+
+	// The rename happened, so appends must go to the new file from now on
+	s.file.Close()
+	s.file = tmp
+	s.records = len(ops)
+	if _, err := s.file.Seek(0, io.SeekEnd); err != nil {
+		return err
+	}
+	return syncDir(filepath.Dir(s.path))
+}
+
+func (s *Store) writeRecord(w io.Writer, ops []Op) error {
+	payload := encodeOps(ops)
+	header := make([]byte, headerSize)
+	binary.BigEndian.PutUint32(header[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(header[4:8], crc32.ChecksumIEEE(payload))
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	_, err := w.Write(payload)
+	return err
+}
+
+func encodeOps(ops []Op) []byte {
+	var buf bytes.Buffer
+	varint := make([]byte, binary.MaxVarintLen64)
+	for _, op := range ops {
+		if op.Delete {
+			buf.WriteByte(opDelete)
+		} else {
+			buf.WriteByte(opPut)
+		}
+		n := binary.PutUvarint(varint, uint64(len(op.Key)))
+		buf.Write(varint[:n])
+		buf.WriteString(op.Key)
+		if !op.Delete {
+			n = binary.PutUvarint(varint, uint64(len(op.Value)))
+			buf.Write(varint[:n])
+			buf.Write(op.Value)
+		}
+	}
+	return buf.Bytes()
+}
+
+func decodeOps(payload []byte) ([]Op, error) {
+	reader := bytes.NewReader(payload)
+	var ops []Op
+	for reader.Len() > 0 {
+		kind, _ := reader.ReadByte()
+		key, err := readBytes(reader)
+		if err != nil {
+			return nil, err
+		}
+		switch kind {
+		case opPut:
+			value, err := readBytes(reader)
+			if err != nil {
+				return nil, err
+			}
+			ops = append(ops, Op{Key: string(key), Value: value})
+		case opDelete:
+			ops = append(ops, Op{Key: string(key), Delete: true})
+		default:
+			return nil, ErrCorrupt
+		}
+	}
+	return ops, nil
+}
+
+func readBytes(reader *bytes.Reader) ([]byte, error) {
+	size, err := binary.ReadUvarint(reader)
+	if err != nil {
+		return nil, ErrCorrupt
+	}
+	if size > uint64(reader.Len()) {
+		return nil, ErrCorrupt
+	}
+	b := make([]byte, size)
+	_, err = io.ReadFull(reader, b)
+	return b, err
+}
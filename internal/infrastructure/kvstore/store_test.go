@@ -0,0 +1,251 @@
+package kvstore
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func openStore(t *testing.T, path string) *Store {
+	t.Helper()
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func put(key, value string) Op { return Op{Key: key, Value: []byte(value)} }
+
+func apply(t *testing.T, s *Store, ops ...Op) {
+	t.Helper()
+	if err := s.Apply(ops); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// wantData checks s holds exactly want
+func wantData(t *testing.T, s *Store, want map[string]string) {
+	t.Helper()
+	keys := s.Scan("")
+	if len(keys) != len(want) {
+		t.Errorf("store holds keys %q, want %d keys", keys, len(want))
+	}
+	for k, v := range want {
+		if got, ok := s.Get(k); !ok || string(got) != v {
+			t.Errorf("%s = %q (present: %v), want %q", k, got, ok, v)
+		}
+	}
+}
+
+func fileSize(t *testing.T, path string) int64 {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info.Size()
+}
+
+func TestReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.db")
+	s := openStore(t, path)
+	apply(t, s, put("a", "1"), put("b", "2"))
+	apply(t, s, put("a", "3"), Op{Key: "b", Delete: true}, put("c", ""))
+	apply(t, s, put("d/1", "x"), put("d/2", "y"))
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s = openStore(t, path)
+	defer s.Close()
+	wantData(t, s, map[string]string{"a": "3", "c": "", "d/1": "x", "d/2": "y"})
+	if keys := s.Scan("d/"); len(keys) != 2 || keys[0] != "d/1" || keys[1] != "d/2" {
+		t.Errorf("Scan(d/) = %q, want [d/1 d/2]", keys)
+	}
+}
+
+func TestOpenLocksTheStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.db")
+	s := openStore(t, path)
+	if _, err := Open(path); err == nil {
+		t.Fatal("a second Open of an open store succeeded")
+	}
+	s.Close()
+	openStore(t, path).Close()
+}
+
+func TestTornTail(t *testing.T) {
+	tests := []struct {
+		name string
+		// tear damages the last record, which starts at offset last
+		tear func(path string, last int64) error
+	}{
+		{"header cut short", func(path string, last int64) error { return os.Truncate(path, last+3) }},
+		{"payload cut short", func(path string, last int64) error { return os.Truncate(path, last+headerSize+2) }},
+		{"payload never written", func(path string, last int64) error {
+			f, err := os.OpenFile(path, os.O_WRONLY, 0)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			// The file grew but the blocks hold zeros
+			info, err := f.Stat()
+			if err != nil {
+				return err
+			}
+			_, err = f.WriteAt(make([]byte, info.Size()-last-headerSize), last+headerSize)
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "words.db")
+			s := openStore(t, path)
+			apply(t, s, put("a", "1"), put("b", "2"))
+			last := fileSize(t, path)
+			apply(t, s, put("a", "changed"), put("c", "3"))
+			s.Close()
+
+			if err := tt.tear(path, last); err != nil {
+				t.Fatal(err)
+			}
+			s = openStore(t, path)
+			wantData(t, s, map[string]string{"a": "1", "b": "2"})
+			if size := fileSize(t, path); size != last {
+				t.Errorf("file is %d bytes after open, want the torn record cut to %d", size, last)
+			}
+
+			// New records follow the last intact one
+			apply(t, s, put("c", "4"))
+			s.Close()
+			s = openStore(t, path)
+			defer s.Close()
+			wantData(t, s, map[string]string{"a": "1", "b": "2", "c": "4"})
+		})
+	}
+}
+
+func TestCorruptRecordRefusesToOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.db")
+	s := openStore(t, path)
+	apply(t, s, put("a", "1"))
+	apply(t, s, put("b", "2"))
+	s.Close()
+
+	// Flip a byte of the first record's payload
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[headerSize+2] ^= 0xff
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Open(path); !errors.Is(err, ErrCorrupt) {
+		t.Fatalf("Open: %v, want ErrCorrupt", err)
+	}
+	if size := fileSize(t, path); size != int64(len(data)) {
+		t.Errorf("Open truncated the corrupt file to %d bytes", size)
+	}
+	// The failed Open released the lock
+	if s, err := Open(path); !errors.Is(err, ErrCorrupt) {
+		if err == nil {
+			s.Close()
+		}
+		t.Fatalf("second Open: %v, want ErrCorrupt", err)
+	}
+}
+
+func TestCorruptLengthRefusesToOpen(t *testing.T) {
+	tests := []struct {
+		name string
+		// length is the damaged length of the second of three records
+		length func(size, second int64) uint32
+	}{
+		{"past the end", func(size, second int64) uint32 { return 1 << 30 }},
+		{"just past the end", func(size, second int64) uint32 { return uint32(size-second-headerSize) + 1 }},
+		{"to the end", func(size, second int64) uint32 { return uint32(size - second - headerSize) }},
+		{"short of the end", func(size, second int64) uint32 { return 3 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "words.db")
+			s := openStore(t, path)
+			apply(t, s, put("a", "1"))
+			second := fileSize(t, path)
+			apply(t, s, put("b", "2"))
+			apply(t, s, put("c", "3"), put("d", "4"))
+			s.Close()
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			binary.BigEndian.PutUint32(data[second:], tt.length(int64(len(data)), second))
+			if err := os.WriteFile(path, data, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			if _, err := Open(path); !errors.Is(err, ErrCorrupt) {
+				t.Fatalf("Open: %v, want ErrCorrupt", err)
+			}
+			if size := fileSize(t, path); size != int64(len(data)) {
+				t.Errorf("Open truncated the file to %d bytes, dropping the records after the damage", size)
+			}
+		})
+	}
+}
+
+func TestCompaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.db")
+	s := openStore(t, path)
+
+	// Rewriting the same keys leaves mostly superseded records
+	want := make(map[string]string)
+	for round := range 30 {
+		var ops []Op
+		for k := range 50 {
+			key, value := fmt.Sprintf("key/%02d", k), fmt.Sprintf("%d", round)
+			ops = append(ops, put(key, value))
+			want[key] = value
+		}
+		apply(t, s, ops...)
+	}
+	apply(t, s, Op{Key: "key/00", Delete: true})
+	delete(want, "key/00")
+
+	if s.records > compactMinRecords {
+		t.Errorf("%d records after 1501 writes to 50 keys, want a compaction", s.records)
+	}
+	if _, err := os.Stat(path + compactSuffix); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("compaction left %s behind", path+compactSuffix)
+	}
+	wantData(t, s, want)
+	s.Close()
+
+	s = openStore(t, path)
+	defer s.Close()
+	wantData(t, s, want)
+}
+
+func TestOpenRemovesAnUnfinishedCompaction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.db")
+	s := openStore(t, path)
+	apply(t, s, put("a", "1"))
+	s.Close()
+	if err := os.WriteFile(path+compactSuffix, []byte("half a compaction"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s = openStore(t, path)
+	defer s.Close()
+	wantData(t, s, map[string]string{"a": "1"})
+	if _, err := os.Stat(path + compactSuffix); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Open left %s behind", path+compactSuffix)
+	}
+}
@@ -0,0 +1,62 @@
+package kvstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"service-matrix-go/internal/core/domain"
+)
+
+const submissionPrefix = "submission/"
+
+// SubmissionRepository keeps the moderation queue in a Store, one JSON
+// value per submission under "submission/<id>"
+type SubmissionRepository struct {
+	store *Store
+}
+
+func NewSubmissionRepository(store *Store) *SubmissionRepository {
+	return &SubmissionRepository{store: store}
+}
+
+// List returns every submission, oldest first
+func (r *SubmissionRepository) List(_ context.Context) ([]domain.Submission, error) {
+	var subs []domain.Submission
+	for _, key := range r.store.Scan(submissionPrefix) {
+		value, ok := r.store.Get(key)
+		if !ok {
+			continue
+		}
+		var sub domain.Submission
+		if err := json.Unmarshal(value, &sub); err != nil {
+			return nil, fmt.Errorf("kvstore: %s: %w", key, err)
+		}
+		subs = append(subs, sub)
+	}
+	sort.SliceStable(subs, func(i, j int) bool {
+		return subs[i].SubmittedAt.Before(subs[j].SubmittedAt)
+	})
+	return subs, nil
+}
+
+// Get returns one submission
+func (r *SubmissionRepository) Get(_ context.Context, id string) (domain.Submission, error) {
+	value, ok := r.store.Get(submissionPrefix + id)
+	if !ok {
+		return domain.Submission{}, fmt.Errorf("%w: submission %q", domain.ErrNotFound, id)
+	}
+	var sub domain.Submission
+	err := json.Unmarshal(value, &sub)
+	return sub, err
+}
+
+// Save inserts or replaces a submission
+func (r *SubmissionRepository) Save(_ context.Context, sub domain.Submission) error {
+	value, err := json.Marshal(sub)
+	if err != nil {
+		return err
+	}
+	return r.store.Apply([]Op{{Key: submissionPrefix + sub.ID, Value: value}})
+}
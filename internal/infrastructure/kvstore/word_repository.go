@@ -0,0 +1,156 @@
+package kvstore
+
+import (
+	"context"
+	"encoding/binary"
+	"sort"
+	"sync"
+
+	"service-matrix-go/internal/core/ports"
+)
+
+// WordRepository stores word lists in a Store. Each word is a key
+// "list/<language>/<list>/<word>" whose value is its 8-byte insertion
+// sequence, which List uses to return words in order.
+type WordRepository struct {
+	store *Store
+
+	// mu makes read-modify-write cycles atomic
+	mu sync.Mutex
+}
+
+func NewWordRepository(store *Store) *WordRepository {
+	return &WordRepository{store: store}
+}
+
+// List returns the words of a list in insertion order
+func (r *WordRepository) List(_ context.Context, ref ports.ListRef) ([]string, error) {
+	words, _ := r.entries(ref)
+	return words, nil
+}
+
+// Add appends the words not yet in the list
+func (r *WordRepository) Add(_ context.Context, ref ports.ListRef, words []string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, next := r.entries(ref)
+	seen := make(map[string]bool)
+	var added []string
+	var ops []Op
+	for _, w := range words {
+		key := listKey(ref, w)
+		if _, exists := r.store.Get(key); exists || seen[w] {
+			continue
+		}
+		seen[w] = true
+		added = append(added, w)
+		ops = append(ops, Op{Key: key, Value: encodeSeq(next)})
+		next++
+	}
+	if err := r.store.Apply(ops); err != nil {
+		return nil, err
+	}
+	return added, nil
+}
+
+// Remove deletes the given words
+func (r *WordRepository) Remove(_ context.Context, ref ports.ListRef, words []string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	seen := make(map[string]bool)
+	var removed []string
+	var ops []Op
+	for _, w := range words {
+		key := listKey(ref, w)
+		if _, exists := r.store.Get(key); !exists || seen[w] {
+			continue
+		}
+		seen[w] = true
+		removed = append(removed, w)
+		ops = append(ops, Op{Key: key, Delete: true})
+	}
+	if err := r.store.Apply(ops); err != nil {
+		return nil, err
+	}
+	return removed, nil
+}
+
+// Contains reports whether word is in the list
+func (r *WordRepository) Contains(_ context.Context, ref ports.ListRef, word string) (bool, error) {
+	_, ok := r.store.Get(listKey(ref, word))
+	return ok, nil
+}
+
+// Replace overwrites the list in a single batch
+func (r *WordRepository) Replace(_ context.Context, ref ports.ListRef, words []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var ops []Op
+	for _, key := range r.store.Scan(listPrefix(ref)) {
+		ops = append(ops, Op{Key: key, Delete: true})
+	}
+	seen := make(map[string]bool)
+	var seq uint64
+	for _, w := range words {
+		if seen[w] {
+			continue
+		}
+		seen[w] = true
+		ops = append(ops, Op{Key: listKey(ref, w), Value: encodeSeq(seq)})
+		seq++
+	}
+	return r.store.Apply(ops)
+}
+
+// entries returns the list in order and the next free sequence number
+func (r *WordRepository) entries(ref ports.ListRef) ([]string, uint64) {
+	prefix := listPrefix(ref)
+	type item struct {
+		word string
+		seq  uint64
+	}
+	var items []item
+	var next uint64
+	for _, key := range r.store.Scan(prefix) {
+		value, ok := r.store.Get(key)
+		if !ok {
+			continue
+		}
+		seq := decodeSeq(value)
+		items = append(items, item{word: key[len(prefix):], seq: seq})
+		if seq >= next {
+			next = seq + 1
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].seq < items[j].seq })
+
+	words := make([]string, len(items))
+	for i, it := range items {
+		words[i] = it.word
+	}
+	return words, next
+}
+
+func listPrefix(ref ports.ListRef) string {
+	return "list/" + ref.Language + "/" + string(ref.Name) + "/"
+}
+
+func listKey(ref ports.ListRef, word string) string {
+	return listPrefix(ref) + word
+}
+
+func encodeSeq(seq uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, seq)
+	return b
+}
+
+func decodeSeq(b []byte) uint64 {
+	if len(b) != 8 {
+		return 0
+	}
+	return binary.BigEndian.Uint64(b)
+}
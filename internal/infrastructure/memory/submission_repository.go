@@ -0,0 +1,53 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"service-matrix-go/internal/core/domain"
+)
+
+// SubmissionRepository keeps the moderation queue in memory
+type SubmissionRepository struct {
+	mu    sync.RWMutex
+	order []string
+	subs  map[string]domain.Submission
+}
+
+func NewSubmissionRepository() *SubmissionRepository {
+	return &SubmissionRepository{subs: make(map[string]domain.Submission)}
+}
+
+// List returns every submission, oldest first
+func (r *SubmissionRepository) List(_ context.Context) ([]domain.Submission, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	subs := make([]domain.Submission, 0, len(r.order))
+	for _, id := range r.order {
+		subs = append(subs, r.subs[id])
+	}
+	return subs, nil
+}
+
+// Get returns one submission
+func (r *SubmissionRepository) Get(_ context.Context, id string) (domain.Submission, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	sub, ok := r.subs[id]
+	if !ok {
+		return domain.Submission{}, fmt.Errorf("%w: submission %q", domain.ErrNotFound, id)
+	}
+	return sub, nil
+}
+
+// Save inserts or replaces a submission
+func (r *SubmissionRepository) Save(_ context.Context, sub domain.Submission) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.subs[sub.ID]; !exists {
+		r.order = append(r.order, sub.ID)
+	}
+	r.subs[sub.ID] = sub
+	return nil
+}
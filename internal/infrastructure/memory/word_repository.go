@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"service-matrix-go/internal/core/ports"
+)
+
+// WordRepository keeps word lists in memory. It is meant for tests and
+// throwaway instances; nothing survives a restart.
+type WordRepository struct {
+	mu    sync.RWMutex
+	lists map[ports.ListRef][]string
+}
+
+func NewWordRepository() *WordRepository {
+	return &WordRepository{lists: make(map[ports.ListRef][]string)}
+}
+
+// List returns a copy of the list
+func (r *WordRepository) List(_ context.Context, ref ports.ListRef) ([]string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]string(nil), r.lists[ref]...), nil
+}
+
+// Add appends the words not yet in the list
+func (r *WordRepository) Add(_ context.Context, ref ports.ListRef, words []string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing := make(map[string]bool)
+	for _, w := range r.lists[ref] {
+		existing[w] = true
+	}
+	var added []string
+	for _, w := range words {
+		if existing[w] {
+			continue
+		}
+		existing[w] = true
+		added = append(added, w)
+	}
+	r.lists[ref] = append(r.lists[ref], added...)
+	return added, nil
+}
+
+// Remove deletes the words, keeping the order of the rest
+func (r *WordRepository) Remove(_ context.Context, ref ports.ListRef, words []string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	remaining, removed := ports.SplitRemoved(r.lists[ref], words)
+	r.lists[ref] = remaining
+	return removed, nil
+}
+
+// Contains reports whether word is in the list
+func (r *WordRepository) Contains(_ context.Context, ref ports.ListRef, word string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, w := range r.lists[ref] {
+		if w == word {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Replace overwrites the list with a copy of words
+func (r *WordRepository) Replace(_ context.Context, ref ports.ListRef, words []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lists[ref] = append([]string(nil), words...)
+	return nil
+}
@@ -0,0 +1,63 @@
+// Package replication reads a leader instance's changes feed over HTTP
+package replication
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"service-matrix-go/internal/core/domain"
+)
+
+// LeaderClient fetches pages of a leader's GET /changes feed
+type LeaderClient struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+// NewLeaderClient reads the feed of the instance at baseURL, e.g.
+// "http://leader:8080", presenting apiKey unless it is empty
+func NewLeaderClient(baseURL, apiKey string, timeout time.Duration) *LeaderClient {
+	return &LeaderClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+// Changes returns up to limit events newer than since
+func (c *LeaderClient) Changes(ctx context.Context, since int64, limit int) (domain.ChangesResponse, error) {
+	query := url.Values{}
+	query.Set("since", strconv.FormatInt(since, 10))
+	query.Set("limit", strconv.Itoa(limit))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/changes?"+query.Encode(), nil)
+	if err != nil {
+		return domain.ChangesResponse{}, err
+	}
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return domain.ChangesResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return domain.ChangesResponse{}, fmt.Errorf("leader returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	var res domain.ChangesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return domain.ChangesResponse{}, fmt.Errorf("decoding leader changes: %w", err)
+	}
+	return res, nil
+}
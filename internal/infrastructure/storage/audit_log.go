@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"service-matrix-go/internal/core/domain"
+)
+
+// FileAuditLog keeps the audit log in a JSON-lines file, one entry per line.
+// Entries are only ever appended.
+type FileAuditLog struct {
+	fileHelper *FileHelper
+	directory  string
+	fileName   string
+}
+
+func NewFileAuditLog(fh *FileHelper, directory, fileName string) *FileAuditLog {
+	return &FileAuditLog{fileHelper: fh, directory: directory, fileName: fileName}
+}
+
+// Append records an entry
+func (l *FileAuditLog) Append(_ context.Context, entry domain.AuditEntry) error {
+	encoded, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return l.fileHelper.WriteFileAppend([]string{string(encoded)}, l.directory, l.fileName)
+}
+
+// Entries returns every entry, oldest first
+func (l *FileAuditLog) Entries(_ context.Context) ([]domain.AuditEntry, error) {
+	lines, err := l.fileHelper.ReadFileAsync(l.directory, l.fileName)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	entries := make([]domain.AuditEntry, 0, len(lines))
+	for i, line := range lines {
+		if line == "" {
+			continue
+		}
+		var entry domain.AuditEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return nil, fmt.Errorf("%w: audit log line %d: %v", ErrCorruptFile, i+1, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"service-matrix-go/internal/core/domain"
+)
+
+// FileChangeLog keeps the changes feed in a JSON-lines file, one event per
+// line in version order. The file lock is held while the next version is
+// picked, so versions stay unique across writers.
+type FileChangeLog struct {
+	fileHelper *FileHelper
+	directory  string
+	fileName   string
+}
+
+func NewFileChangeLog(fh *FileHelper, directory, fileName string) *FileChangeLog {
+	return &FileChangeLog{fileHelper: fh, directory: directory, fileName: fileName}
+}
+
+// Append stores event under the next version. Versions only go up, so the
+// next one follows the last event's and the rest of the feed isn't decoded.
+func (l *FileChangeLog) Append(_ context.Context, event domain.ChangeEvent) (domain.ChangeEvent, error) {
+	var appendErr error
+	err := l.fileHelper.AppendFileFunc(l.directory, l.fileName, func(lines []string) []string {
+		last, err := lastChangeEvent(lines)
+		if err != nil {
+			appendErr = err
+			return nil
+		}
+		event.Version = last.Version + 1
+		encoded, err := json.Marshal(event)
+		if err != nil {
+			appendErr = err
+			return nil
+		}
+		return []string{string(encoded)}
+	})
+	if err != nil {
+		return domain.ChangeEvent{}, err
+	}
+	return event, appendErr
+}
+
+// Since returns up to limit events newer than version
+func (l *FileChangeLog) Since(_ context.Context, version int64, limit int) (domain.ChangesResponse, error) {
+	res := domain.ChangesResponse{Changes: []domain.ChangeEvent{}}
+	lines, err := l.fileHelper.ReadFileAsync(l.directory, l.fileName)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return res, nil
+		}
+		return res, err
+	}
+	events, err := decodeChangeEvents(lines)
+	if err != nil {
+		return res, err
+	}
+	for _, event := range events {
+		if event.Version <= version {
+			continue
+		}
+		if limit > 0 && len(res.Changes) == limit {
+			break
+		}
+		res.Changes = append(res.Changes, event)
+	}
+	if len(events) > 0 {
+		res.Latest = events[len(events)-1].Version
+	}
+	return res, nil
+}
+
+// lastChangeEvent decodes the last event of the feed, or returns the zero
+// event for an empty one
+func lastChangeEvent(lines []string) (domain.ChangeEvent, error) {
+	var event domain.ChangeEvent
+	for i := len(lines) - 1; i >= 0; i-- {
+		if lines[i] == "" {
+			continue
+		}
+		if err := json.Unmarshal([]byte(lines[i]), &event); err != nil {
+			return event, fmt.Errorf("%w: changes line %d: %v", ErrCorruptFile, i+1, err)
+		}
+		break
+	}
+	return event, nil
+}
+
+func decodeChangeEvents(lines []string) ([]domain.ChangeEvent, error) {
+	events := make([]domain.ChangeEvent, 0, len(lines))
+	for i, line := range lines {
+		if line == "" {
+			continue
+		}
+		var event domain.ChangeEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			return nil, fmt.Errorf("%w: changes line %d: %v", ErrCorruptFile, i+1, err)
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
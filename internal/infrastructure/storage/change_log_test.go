@@ -0,0 +1,91 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"service-matrix-go/internal/core/domain"
+)
+
+func appendEvent(t *testing.T, l *FileChangeLog, language string) domain.ChangeEvent {
+	t.Helper()
+	event, err := l.Append(context.Background(), domain.ChangeEvent{Operation: domain.OperationAdd, Language: language})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return event
+}
+
+func TestChangeLogVersions(t *testing.T) {
+	dir := t.TempDir()
+	l := NewFileChangeLog(NewFileHelper(dir), "data", "changes.jsonl")
+
+	for want := int64(1); want <= 3; want++ {
+		if got := appendEvent(t, l, "ru").Version; got != want {
+			t.Fatalf("appended version %d, want %d", got, want)
+		}
+	}
+
+	// Another log on the same file, e.g. after a restart, carries on
+	l = NewFileChangeLog(NewFileHelper(dir), "data", "changes.jsonl")
+	if got := appendEvent(t, l, "en").Version; got != 4 {
+		t.Errorf("after reopening appended version %d, want 4", got)
+	}
+
+	res, err := l.Since(context.Background(), 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Latest != 4 || len(res.Changes) != 2 || res.Changes[0].Version != 3 || res.Changes[1].Language != "en" {
+		t.Errorf("Since(2) = %+v, want versions 3 and 4 of 4", res)
+	}
+}
+
+func TestChangeLogConcurrentAppends(t *testing.T) {
+	dir := t.TempDir()
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Separate logs share only the file and its lock
+			appendEvent(t, NewFileChangeLog(NewFileHelper(dir), "data", "changes.jsonl"), "ru")
+		}()
+	}
+	wg.Wait()
+
+	res, err := NewFileChangeLog(NewFileHelper(dir), "data", "changes.jsonl").Since(context.Background(), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, event := range res.Changes {
+		if event.Version != int64(i+1) {
+			t.Fatalf("event %d has version %d; versions must be unique and in order", i, event.Version)
+		}
+	}
+	if len(res.Changes) != 20 || res.Latest != 20 {
+		t.Errorf("feed holds %d events up to %d, want 20", len(res.Changes), res.Latest)
+	}
+}
+
+func TestChangeLogRefusesADamagedTail(t *testing.T) {
+	dir := t.TempDir()
+	fh := NewFileHelper(dir)
+	l := NewFileChangeLog(fh, "data", "changes.jsonl")
+	appendEvent(t, l, "ru")
+
+	// A hand edit that was sealed but isn't an event
+	if err := os.WriteFile(filepath.Join(dir, "data", "changes.jsonl"), []byte(`{"version": 1}`+"\nnot json\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := fh.Seal("data", "changes.jsonl"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Append(context.Background(), domain.ChangeEvent{Language: "ru"}); !errors.Is(err, ErrCorruptFile) {
+		t.Errorf("Append after a damaged last line: %v, want ErrCorruptFile", err)
+	}
+}
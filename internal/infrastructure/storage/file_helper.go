@@ -2,11 +2,36 @@ package storage
 
 import (
 	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"service-matrix-go/internal/infrastructure/filelock"
 )
 
-// FileHelper provides methods for file I/O
+// ErrCorruptFile is returned when a file fails its integrity checks on load
+var ErrCorruptFile = errors.New("corrupt file")
+
+// checksumSuffix names the sidecar file holding a file's accepted checksums
+const checksumSuffix = ".sha256"
+
+// FileHelper provides methods for file I/O.
+//
+// Writes are crash safe: full rewrites go to a temp file that is fsynced and
+// renamed over the target, and every writer of a file holds an advisory lock
+// on "<file>.lock", which serialises writers within the process and across
+// processes. Each write also records the file's checksum in "<file>.sha256" so
+// a torn or otherwise damaged file is detected the next time it is read.
+// Readers take no lock, so read-only directories such as a mounted resources
+// dir work; a reader that catches a file and checksum from different writes
+// reads both again. After editing a list by hand, Seal it.
 type FileHelper struct {
 	BaseDir string
 }
@@ -15,67 +40,310 @@ func NewFileHelper(baseDir string) *FileHelper {
 	return &FileHelper{BaseDir: baseDir}
 }
 
-// ReadFileAsync reads lines from a file
-func (h *FileHelper) ReadFileAsync(directory, fileName string) ([]string, error) {
+// Path returns the file ReadFileAsync reads for directory and fileName
+func (h *FileHelper) Path(directory, fileName string) string {
 	filePath := filepath.Join(h.BaseDir, directory, fileName)
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
 		// Fallback to relative path if not found in base dir
 		filePath = filepath.Join(directory, fileName)
 	}
+	return filePath
+}
 
-	file, err := os.Open(filePath)
+// ReadFileAsync reads lines from a file, checked against its checksum
+func (h *FileHelper) ReadFileAsync(directory, fileName string) ([]string, error) {
+	filePath := h.Path(directory, fileName)
+	data, err := readVerified(filePath)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
+	return splitLines(data)
+}
 
-	var lines []string
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
+// readRetries is how many times readVerified reads a file whose checksum
+// doesn't match before calling it corrupt
+const readRetries = 5
+
+// readVerified reads path and checks it against its checksum without taking
+// the lock. A writer updates the file and its checksum one after the other, so
+// a mismatch may just mean the read raced a write: it is only corruption if
+// the file and its checksum read the same again.
+func readVerified(path string) ([]byte, error) {
+	var lastData, lastSums []byte
+	var lastErr error
+	for attempt := range readRetries {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		err = verify(path, data)
+		if err == nil {
+			return data, nil
+		}
+		sums, _ := os.ReadFile(path + checksumSuffix)
+		if attempt > 0 && bytes.Equal(data, lastData) && bytes.Equal(sums, lastSums) {
+			return nil, err
+		}
+		lastData, lastSums, lastErr = data, sums, err
+		time.Sleep(time.Duration(attempt+1) * 5 * time.Millisecond)
+	}
+	return nil, lastErr
+}
+
+// Checksum returns the checksums recorded for a file, or "" when it has none.
+// It changes with every write, so callers can tell whether a file they read
+// before is still the same without hashing it again.
+func (h *FileHelper) Checksum(directory, fileName string) (string, error) {
+	sums, err := os.ReadFile(h.Path(directory, fileName) + checksumSuffix)
+	if os.IsNotExist(err) {
+		return "", nil
 	}
+	return strings.TrimSpace(string(sums)), err
+}
 
-	return lines, scanner.Err()
+// Seal records the file's current contents as good, for a file edited by
+// hand. It still refuses content no writer of ours could have produced.
+func (h *FileHelper) Seal(directory, fileName string) error {
+	return h.withLock(directory, fileName, func(filePath string) error {
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		if err := checkText(filePath, data); err != nil {
+			return err
+		}
+		return writeAtomic(filePath+checksumSuffix, []byte(checksum(data)+"\n"))
+	})
 }
 
-// WriteFileNewContents writes content to a file, overwriting it
+// WriteFileNewContents writes content to a file, overwriting it atomically
 func (h *FileHelper) WriteFileNewContents(contents []string, directory, fileName string) error {
+	return h.withLock(directory, fileName, func(filePath string) error {
+		return replaceLocked(filePath, contents)
+	})
+}
+
+// WriteFileAppend appends content to a file
+func (h *FileHelper) WriteFileAppend(contents []string, directory, fileName string) error {
+	return h.withLock(directory, fileName, func(filePath string) error {
+		return appendLocked(filePath, contents)
+	})
+}
+
+// UpdateFile replaces a file with the lines fn derives from its current
+// lines, holding the file lock throughout so no other writer can interleave.
+// A missing file reads as empty. If fn returns nil lines the file is left alone.
+func (h *FileHelper) UpdateFile(directory, fileName string, fn func(lines []string) ([]string, error)) error {
+	return h.withLock(directory, fileName, func(filePath string) error {
+		lines, err := readLocked(filePath)
+		if err != nil {
+			return err
+		}
+		updated, err := fn(lines)
+		if err != nil || updated == nil {
+			return err
+		}
+		return replaceLocked(filePath, updated)
+	})
+}
+
+// AppendFileFunc appends the lines fn picks given the file's current lines,
+// holding the file lock throughout. A missing file reads as empty.
+func (h *FileHelper) AppendFileFunc(directory, fileName string, fn func(lines []string) []string) error {
+	return h.withLock(directory, fileName, func(filePath string) error {
+		lines, err := readLocked(filePath)
+		if err != nil {
+			return err
+		}
+		if toAppend := fn(lines); len(toAppend) > 0 {
+			return appendLocked(filePath, toAppend)
+		}
+		return nil
+	})
+}
+
+// withLock runs fn with the file's directory created and its lock held
+func (h *FileHelper) withLock(directory, fileName string, fn func(filePath string) error) error {
 	filePath := filepath.Join(h.BaseDir, directory, fileName)
 	// Ensure directory exists
 	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
 		return err
 	}
 
-	file, err := os.Create(filePath)
+	lock, err := filelock.Acquire(filePath + ".lock")
 	if err != nil {
 		return err
 	}
-	defer file.Close()
+	defer lock.Unlock()
+	return fn(filePath)
+}
+
+func readLocked(filePath string) ([]string, error) {
+	data, err := os.ReadFile(filePath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := verify(filePath, data); err != nil {
+		return nil, err
+	}
+	return splitLines(data)
+}
 
-	writer := bufio.NewWriter(file)
+func replaceLocked(filePath string, contents []string) error {
+	var buf bytes.Buffer
 	for _, line := range contents {
-		if _, err := writer.WriteString(line + "\n"); err != nil {
-			return err
-		}
+		buf.WriteString(line + "\n")
+	}
+	newSum := checksum(buf.Bytes())
+
+	// Accept both versions while the rename is in flight
+	sums := []string{newSum}
+	if old, err := os.ReadFile(filePath); err == nil {
+		sums = append(sums, checksum(old))
 	}
-	return writer.Flush()
+	if err := writeAtomic(filePath+checksumSuffix, []byte(strings.Join(sums, "\n")+"\n")); err != nil {
+		return err
+	}
+	if err := writeAtomic(filePath, buf.Bytes()); err != nil {
+		return err
+	}
+	return writeAtomic(filePath+checksumSuffix, []byte(newSum+"\n"))
 }
 
-// WriteFileAppend appends content to a file
-func (h *FileHelper) WriteFileAppend(contents []string, directory, fileName string) error {
-	filePath := filepath.Join(h.BaseDir, directory, fileName)
+func appendLocked(filePath string, contents []string) error {
+	old, err := os.ReadFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := verify(filePath, old); err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	// Don't glue the first new word onto a hand-edited last line
+	if len(old) > 0 && old[len(old)-1] != '\n' {
+		buf.WriteByte('\n')
+	}
+	for _, line := range contents {
+		buf.WriteString(line + "\n")
+	}
+	oldSum := checksum(old)
+	newSum := checksum(append(old, buf.Bytes()...))
+
+	// Accept both versions until the append is on disk
+	if err := writeAtomic(filePath+checksumSuffix, []byte(newSum+"\n"+oldSum+"\n")); err != nil {
+		return err
+	}
 
 	file, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
+	if _, err := file.Write(buf.Bytes()); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return writeAtomic(filePath+checksumSuffix, []byte(newSum+"\n"))
+}
+
+// WriteAtomic replaces the file at path with data, crash safely, for files
+// that are not line lists (and so have no lock or checksum of their own)
+func WriteAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return writeAtomic(path, data)
+}
+
+// writeAtomic writes data to a temp file next to path, fsyncs it and
+// renames it over path
+func writeAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
 
-	writer := bufio.NewWriter(file)
-	for _, line := range contents {
-		if _, err := writer.WriteString(line + "\n"); err != nil {
-			return err
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, 0644); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return syncDir(dir)
+}
+
+// verify checks data against the recorded checksums of path, if any, and
+// rejects content no writer of ours could have produced
+func verify(path string, data []byte) error {
+	if err := checkText(path, data); err != nil {
+		return err
+	}
+
+	sums, err := os.ReadFile(path + checksumSuffix)
+	if err != nil {
+		// Files we never wrote (or hand-managed ones) have no checksum
+		return nil
+	}
+	actual := checksum(data)
+	for _, sum := range strings.Fields(string(sums)) {
+		if sum == actual {
+			return nil
 		}
 	}
-	return writer.Flush()
+	return fmt.Errorf("%w: %s does not match its checksum; if it was edited by hand, seal it with matrixctl seal", ErrCorruptFile, path)
+}
+
+// checkText rejects data that isn't a text file
+func checkText(path string, data []byte) error {
+	if bytes.IndexByte(data, 0) >= 0 {
+		return fmt.Errorf("%w: %s contains NUL bytes", ErrCorruptFile, path)
+	}
+	if !utf8.Valid(data) {
+		return fmt.Errorf("%w: %s is not valid UTF-8", ErrCorruptFile, path)
+	}
+	return nil
+}
+
+func splitLines(data []byte) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
 }
@@ -0,0 +1,300 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"service-matrix-go/internal/infrastructure/filelock"
+)
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestWriteFileNewContentsIsAtomic(t *testing.T) {
+	dir := t.TempDir()
+	h := NewFileHelper(dir)
+	path := filepath.Join(dir, "data", "include.txt")
+
+	for _, contents := range [][]string{{"кот", "дом"}, {"лес"}} {
+		if err := h.WriteFileNewContents(contents, "data", "include.txt"); err != nil {
+			t.Fatal(err)
+		}
+		want := strings.Join(contents, "\n") + "\n"
+		if got := readFile(t, path); got != want {
+			t.Errorf("file holds %q, want %q", got, want)
+		}
+		if got := readFile(t, path+checksumSuffix); got != checksum([]byte(want))+"\n" {
+			t.Errorf("checksum file holds %q, want only the new checksum", got)
+		}
+	}
+
+	temps, _ := filepath.Glob(filepath.Join(dir, "data", ".*.tmp-*"))
+	if len(temps) > 0 {
+		t.Errorf("temp files left behind: %q", temps)
+	}
+}
+
+func TestWriteFileAppend(t *testing.T) {
+	dir := t.TempDir()
+	h := NewFileHelper(dir)
+	path := filepath.Join(dir, "data", "include.txt")
+
+	if err := h.WriteFileAppend([]string{"кот"}, "data", "include.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.WriteFileAppend([]string{"дом", "лес"}, "data", "include.txt"); err != nil {
+		t.Fatal(err)
+	}
+	lines, err := h.ReadFileAsync("data", "include.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"кот", "дом", "лес"}; !slices.Equal(lines, want) {
+		t.Errorf("read %q, want %q", lines, want)
+	}
+
+	// A hand-edited last line without a newline keeps its own line
+	os.Remove(path + checksumSuffix)
+	if err := os.WriteFile(path, []byte("кот\nдом"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.WriteFileAppend([]string{"лес"}, "data", "include.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := readFile(t, path), "кот\nдом\nлес\n"; got != want {
+		t.Errorf("file holds %q, want %q", got, want)
+	}
+}
+
+func TestChecksums(t *testing.T) {
+	tests := []struct {
+		name    string
+		damage  func(path string) error
+		wantErr bool
+	}{
+		{"intact", func(string) error { return nil }, false},
+		{"edited", func(path string) error { return os.WriteFile(path, []byte("кот\nмышь\n"), 0644) }, true},
+		{"truncated", func(path string) error { return os.Truncate(path, 3) }, true},
+		{"edited without checksum", func(path string) error {
+			if err := os.WriteFile(path, []byte("кот\nмышь\n"), 0644); err != nil {
+				return err
+			}
+			return os.Remove(path + checksumSuffix)
+		}, false},
+		{"NUL bytes without checksum", func(path string) error {
+			if err := os.WriteFile(path, []byte("кот\x00\n"), 0644); err != nil {
+				return err
+			}
+			return os.Remove(path + checksumSuffix)
+		}, true},
+		{"invalid UTF-8 without checksum", func(path string) error {
+			if err := os.WriteFile(path, []byte("\xff\xfe\n"), 0644); err != nil {
+				return err
+			}
+			return os.Remove(path + checksumSuffix)
+		}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			h := NewFileHelper(dir)
+			if err := h.WriteFileNewContents([]string{"кот", "дом"}, "data", "include.txt"); err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.damage(filepath.Join(dir, "data", "include.txt")); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := h.ReadFileAsync("data", "include.txt")
+			if tt.wantErr != errors.Is(err, ErrCorruptFile) {
+				t.Fatalf("ReadFileAsync: %v, want corrupt: %v", err, tt.wantErr)
+			}
+			// Writers refuse to build on a corrupt file too
+			err = h.WriteFileAppend([]string{"лес"}, "data", "include.txt")
+			if tt.wantErr != errors.Is(err, ErrCorruptFile) {
+				t.Fatalf("WriteFileAppend: %v, want corrupt: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSeal(t *testing.T) {
+	dir := t.TempDir()
+	h := NewFileHelper(dir)
+	path := filepath.Join(dir, "data", "include.txt")
+	if err := h.WriteFileNewContents([]string{"кот"}, "data", "include.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, []byte("кот\nмышь\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.ReadFileAsync("data", "include.txt"); !errors.Is(err, ErrCorruptFile) {
+		t.Fatalf("hand-edited file read with %v, want ErrCorruptFile", err)
+	}
+	if err := h.Seal("data", "include.txt"); err != nil {
+		t.Fatal(err)
+	}
+	lines, err := h.ReadFileAsync("data", "include.txt")
+	if err != nil {
+		t.Fatalf("sealed file: %v", err)
+	}
+	if want := []string{"кот", "мышь"}; !slices.Equal(lines, want) {
+		t.Errorf("read %q, want %q", lines, want)
+	}
+
+	if err := os.WriteFile(path, []byte("кот\x00\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Seal("data", "include.txt"); !errors.Is(err, ErrCorruptFile) {
+		t.Errorf("sealing a file with NUL bytes: %v, want ErrCorruptFile", err)
+	}
+}
+
+func TestReadMissingFile(t *testing.T) {
+	dir := t.TempDir()
+	h := NewFileHelper(dir)
+	if _, err := h.ReadFileAsync("data", "include.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("ReadFileAsync: %v, want not exist", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "data")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("reading created the data directory")
+	}
+}
+
+func TestConcurrentAppends(t *testing.T) {
+	dir := t.TempDir()
+	h := NewFileHelper(dir)
+
+	const writers = 20
+	var wg sync.WaitGroup
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			word := fmt.Sprintf("word%02d", i)
+			err := h.AppendFileFunc("data", "include.txt", func(lines []string) []string {
+				if slices.Contains(lines, word) {
+					return nil
+				}
+				return []string{word}
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	lines, err := h.ReadFileAsync("data", "include.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(lines)
+	if len(lines) != writers || lines[0] != "word00" || lines[writers-1] != fmt.Sprintf("word%02d", writers-1) {
+		t.Errorf("read %d lines %q, want word00 to word%02d", len(lines), lines, writers-1)
+	}
+}
+
+func TestReadDuringWrites(t *testing.T) {
+	dir := t.TempDir()
+	h := NewFileHelper(dir)
+	if err := h.WriteFileNewContents([]string{"кот"}, "data", "include.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range 200 {
+			var err error
+			if i%2 == 0 {
+				err = h.WriteFileNewContents([]string{"кот", fmt.Sprint(i)}, "data", "include.txt")
+			} else {
+				err = h.WriteFileAppend([]string{fmt.Sprint(i)}, "data", "include.txt")
+			}
+			if err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		lines, err := h.ReadFileAsync("data", "include.txt")
+		if err != nil {
+			t.Fatalf("read during a write: %v", err)
+		}
+		if len(lines) == 0 || lines[0] != "кот" {
+			t.Fatalf("read %q during a write", lines)
+		}
+	}
+}
+
+func TestReadTakesNoLock(t *testing.T) {
+	dir := t.TempDir()
+	h := NewFileHelper(dir)
+	if err := h.WriteFileNewContents([]string{"кот"}, "data", "include.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A writer holding the lock doesn't hold up readers
+	lock, err := filelock.Acquire(filepath.Join(dir, "data", "include.txt.lock"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lock.Unlock()
+	lines, err := h.ReadFileAsync("data", "include.txt")
+	if err != nil || !slices.Equal(lines, []string{"кот"}) {
+		t.Errorf("ReadFileAsync = %q, %v; want [кот]", lines, err)
+	}
+
+	// Nor does reading a missing file leave a lock file behind
+	if _, err := h.ReadFileAsync("data", "exclude.txt"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("ReadFileAsync: %v, want not exist", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "data", "exclude.txt.lock")); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("reading a missing file created its lock file")
+	}
+}
+
+func TestReadOnlyDirectory(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root ignores directory permissions")
+	}
+	dir := t.TempDir()
+	h := NewFileHelper(dir)
+	if err := h.WriteFileNewContents([]string{"кот"}, "resources", "merged.txt"); err != nil {
+		t.Fatal(err)
+	}
+	readOnly := filepath.Join(dir, "resources")
+	if err := os.Remove(filepath.Join(readOnly, "merged.txt.lock")); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(readOnly, 0555); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chmod(readOnly, 0755)
+
+	lines, err := h.ReadFileAsync("resources", "merged.txt")
+	if err != nil || !slices.Equal(lines, []string{"кот"}) {
+		t.Errorf("ReadFileAsync = %q, %v; want [кот]", lines, err)
+	}
+}
@@ -0,0 +1,143 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+
+	"service-matrix-go/internal/core/language"
+	"service-matrix-go/internal/core/ports"
+)
+
+// DefaultListFiles maps each list to the file it has always lived in
+var DefaultListFiles = map[ports.ListName]string{
+	ports.ListInclude:       "include.txt",
+	ports.ListExclude:       "exclude.txt",
+	ports.ListMerged:        "merged.txt",
+	ports.ListMergeable:     "mergeable_definitions.txt",
+	ports.ListMergedCleaned: "merged_cleaned.txt",
+}
+
+// FileWordRepository is the flat-file WordRepository: each list is a text
+// file with one word per line. merged.txt lives in the language's resource
+// directory next to the dictionary, every other list in its data directory.
+// Read-modify-write cycles hold the FileHelper's file lock.
+type FileWordRepository struct {
+	fileHelper *FileHelper
+	languages  *language.Registry
+	fileNames  map[ports.ListName]string
+}
+
+func NewFileWordRepository(fh *FileHelper, languages *language.Registry, fileNames map[ports.ListName]string) *FileWordRepository {
+	if fileNames == nil {
+		fileNames = DefaultListFiles
+	}
+	return &FileWordRepository{fileHelper: fh, languages: languages, fileNames: fileNames}
+}
+
+// List reads the list file; a missing file is an empty list
+func (r *FileWordRepository) List(_ context.Context, ref ports.ListRef) ([]string, error) {
+	dir, file, err := r.location(ref)
+	if err != nil {
+		return nil, err
+	}
+	return r.read(dir, file)
+}
+
+// Add appends the words not yet in the file
+func (r *FileWordRepository) Add(_ context.Context, ref ports.ListRef, words []string) ([]string, error) {
+	dir, file, err := r.location(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	var added []string
+	err = r.fileHelper.AppendFileFunc(dir, file, func(existing []string) []string {
+		existingMap := make(map[string]bool, len(existing))
+		for _, w := range existing {
+			existingMap[w] = true
+		}
+		for _, w := range words {
+			if existingMap[w] {
+				continue
+			}
+			existingMap[w] = true
+			added = append(added, w)
+		}
+		return added
+	})
+	if err != nil {
+		return nil, err
+	}
+	return added, nil
+}
+
+// Remove rewrites the file without the given words
+func (r *FileWordRepository) Remove(_ context.Context, ref ports.ListRef, words []string) ([]string, error) {
+	dir, file, err := r.location(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed []string
+	err = r.fileHelper.UpdateFile(dir, file, func(existing []string) ([]string, error) {
+		var remaining []string
+		remaining, removed = ports.SplitRemoved(existing, words)
+		if len(removed) == 0 {
+			return nil, nil
+		}
+		return remaining, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return removed, nil
+}
+
+// Contains scans the list file for word
+func (r *FileWordRepository) Contains(ctx context.Context, ref ports.ListRef, word string) (bool, error) {
+	words, err := r.List(ctx, ref)
+	if err != nil {
+		return false, err
+	}
+	for _, w := range words {
+		if w == word {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Replace overwrites the list file
+func (r *FileWordRepository) Replace(_ context.Context, ref ports.ListRef, words []string) error {
+	dir, file, err := r.location(ref)
+	if err != nil {
+		return err
+	}
+
+	return r.fileHelper.WriteFileNewContents(words, dir, file)
+}
+
+// location resolves a list to its directory and file name
+func (r *FileWordRepository) location(ref ports.ListRef) (string, string, error) {
+	pack, err := r.languages.Get(ref.Language)
+	if err != nil {
+		return "", "", err
+	}
+	file, ok := r.fileNames[ref.Name]
+	if !ok {
+		file = string(ref.Name) + ".txt"
+	}
+	if ref.Name == ports.ListMerged {
+		return pack.ResourceDir, file, nil
+	}
+	return pack.DataDir, file, nil
+}
+
+func (r *FileWordRepository) read(dir, file string) ([]string, error) {
+	lines, err := r.fileHelper.ReadFileAsync(dir, file)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return lines, err
+}
@@ -0,0 +1,185 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"service-matrix-go/internal/core/domain"
+)
+
+// FileSnapshotStore keeps snapshots under one directory: a manifest
+// "<id>.json" per snapshot and the list contents in "blobs/<sha256>.txt".
+// Blobs are content addressed, so an unchanged list (the base dictionary,
+// usually) is stored once however many snapshots refer to it.
+type FileSnapshotStore struct {
+	fileHelper *FileHelper
+	directory  string
+
+	mu sync.Mutex
+}
+
+func NewFileSnapshotStore(fh *FileHelper, directory string) *FileSnapshotStore {
+	return &FileSnapshotStore{fileHelper: fh, directory: directory}
+}
+
+// Save writes the blobs, then the manifest under the next free ID
+func (s *FileSnapshotStore) Save(ctx context.Context, snap domain.Snapshot, lists map[string][]string) (domain.Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snap.Lists = make(map[string]domain.SnapshotList, len(lists))
+	for name, words := range lists {
+		blob := blobName(words)
+		if _, err := os.Stat(s.blobPath(blob)); os.IsNotExist(err) {
+			if err := s.fileHelper.WriteFileNewContents(words, filepath.Join(s.directory, "blobs"), blob+".txt"); err != nil {
+				return domain.Snapshot{}, err
+			}
+		}
+		snap.Lists[name] = domain.SnapshotList{Blob: blob, Count: len(words)}
+	}
+
+	snaps, err := s.list()
+	if err != nil {
+		return domain.Snapshot{}, err
+	}
+	snap.ID = 1
+	if len(snaps) > 0 {
+		snap.ID = snaps[len(snaps)-1].ID + 1
+	}
+
+	manifest, err := json.Marshal(snap)
+	if err != nil {
+		return domain.Snapshot{}, err
+	}
+	if err := s.fileHelper.WriteFileNewContents([]string{string(manifest)}, s.directory, manifestName(snap.ID)); err != nil {
+		return domain.Snapshot{}, err
+	}
+	return snap, nil
+}
+
+// List returns all snapshots, oldest first
+func (s *FileSnapshotStore) List(_ context.Context) ([]domain.Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.list()
+}
+
+// Get returns one snapshot
+func (s *FileSnapshotStore) Get(_ context.Context, id int) (domain.Snapshot, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.get(id)
+}
+
+// ReadList returns the content of one list of a snapshot
+func (s *FileSnapshotStore) ReadList(_ context.Context, snap domain.Snapshot, name string) ([]string, error) {
+	list, ok := snap.Lists[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: snapshot %d has no %s list", domain.ErrNotFound, snap.ID, name)
+	}
+	return s.fileHelper.ReadFileAsync(filepath.Join(s.directory, "blobs"), list.Blob+".txt")
+}
+
+// Delete removes a snapshot and garbage collects its blobs
+func (s *FileSnapshotStore) Delete(_ context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snap, err := s.get(id)
+	if err != nil {
+		return err
+	}
+	if err := s.removeFile(filepath.Join(s.fileHelper.BaseDir, s.directory, manifestName(id))); err != nil {
+		return err
+	}
+
+	remaining, err := s.list()
+	if err != nil {
+		return err
+	}
+	inUse := make(map[string]bool)
+	for _, other := range remaining {
+		for _, list := range other.Lists {
+			inUse[list.Blob] = true
+		}
+	}
+	for _, list := range snap.Lists {
+		if !inUse[list.Blob] {
+			if err := s.removeFile(s.blobPath(list.Blob)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *FileSnapshotStore) list() ([]domain.Snapshot, error) {
+	files, err := filepath.Glob(filepath.Join(s.fileHelper.BaseDir, s.directory, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var snaps []domain.Snapshot
+	for _, file := range files {
+		id, err := strconv.Atoi(strings.TrimSuffix(filepath.Base(file), ".json"))
+		if err != nil {
+			continue
+		}
+		snap, err := s.get(id)
+		if err != nil {
+			return nil, err
+		}
+		snaps = append(snaps, snap)
+	}
+	sort.Slice(snaps, func(i, j int) bool { return snaps[i].ID < snaps[j].ID })
+	return snaps, nil
+}
+
+func (s *FileSnapshotStore) get(id int) (domain.Snapshot, error) {
+	lines, err := s.fileHelper.ReadFileAsync(s.directory, manifestName(id))
+	if os.IsNotExist(err) {
+		return domain.Snapshot{}, fmt.Errorf("%w: snapshot %d", domain.ErrNotFound, id)
+	}
+	if err != nil {
+		return domain.Snapshot{}, err
+	}
+	var snap domain.Snapshot
+	if err := json.Unmarshal([]byte(strings.Join(lines, "\n")), &snap); err != nil {
+		return domain.Snapshot{}, fmt.Errorf("%w: snapshot %d: %v", ErrCorruptFile, id, err)
+	}
+	return snap, nil
+}
+
+func (s *FileSnapshotStore) blobPath(blob string) string {
+	return filepath.Join(s.fileHelper.BaseDir, s.directory, "blobs", blob+".txt")
+}
+
+// removeFile deletes a file together with its checksum and lock files
+func (s *FileSnapshotStore) removeFile(path string) error {
+	for _, p := range []string{path, path + checksumSuffix, path + ".lock"} {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func manifestName(id int) string {
+	return fmt.Sprintf("%06d.json", id)
+}
+
+func blobName(words []string) string {
+	h := sha256.New()
+	for _, w := range words {
+		h.Write([]byte(w + "\n"))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"service-matrix-go/internal/core/domain"
+)
+
+// FileSubmissionRepository keeps the moderation queue in a JSON-lines file,
+// one submission per line, rewritten atomically on every save
+type FileSubmissionRepository struct {
+	fileHelper *FileHelper
+	directory  string
+	fileName   string
+}
+
+func NewFileSubmissionRepository(fh *FileHelper, directory, fileName string) *FileSubmissionRepository {
+	return &FileSubmissionRepository{fileHelper: fh, directory: directory, fileName: fileName}
+}
+
+// List returns every submission, oldest first
+func (r *FileSubmissionRepository) List(_ context.Context) ([]domain.Submission, error) {
+	lines, err := r.fileHelper.ReadFileAsync(r.directory, r.fileName)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return decodeSubmissions(lines)
+}
+
+// Get returns one submission
+func (r *FileSubmissionRepository) Get(ctx context.Context, id string) (domain.Submission, error) {
+	subs, err := r.List(ctx)
+	if err != nil {
+		return domain.Submission{}, err
+	}
+	for _, sub := range subs {
+		if sub.ID == id {
+			return sub, nil
+		}
+	}
+	return domain.Submission{}, fmt.Errorf("%w: submission %q", domain.ErrNotFound, id)
+}
+
+// Save inserts or replaces a submission
+func (r *FileSubmissionRepository) Save(_ context.Context, sub domain.Submission) error {
+	encoded, err := json.Marshal(sub)
+	if err != nil {
+		return err
+	}
+	return r.fileHelper.UpdateFile(r.directory, r.fileName, func(lines []string) ([]string, error) {
+		subs, err := decodeSubmissions(lines)
+		if err != nil {
+			return nil, err
+		}
+		updated := make([]string, 0, len(subs)+1)
+		replaced := false
+		for _, existing := range subs {
+			if existing.ID == sub.ID {
+				updated = append(updated, string(encoded))
+				replaced = true
+				continue
+			}
+			line, err := json.Marshal(existing)
+			if err != nil {
+				return nil, err
+			}
+			updated = append(updated, string(line))
+		}
+		if !replaced {
+			updated = append(updated, string(encoded))
+		}
+		return updated, nil
+	})
+}
+
+func decodeSubmissions(lines []string) ([]domain.Submission, error) {
+	subs := make([]domain.Submission, 0, len(lines))
+	for i, line := range lines {
+		if line == "" {
+			continue
+		}
+		var sub domain.Submission
+		if err := json.Unmarshal([]byte(line), &sub); err != nil {
+			return nil, fmt.Errorf("%w: submissions line %d: %v", ErrCorruptFile, i+1, err)
+		}
+		subs = append(subs, sub)
+	}
+	return subs, nil
+}
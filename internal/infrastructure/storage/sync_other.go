@@ -0,0 +1,6 @@
+//go:build !unix
+
+package storage
+
+// syncDir is a no-op where directories can't be fsynced
+func syncDir(string) error { return nil }
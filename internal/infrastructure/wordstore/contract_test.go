@@ -0,0 +1,220 @@
+package wordstore
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"service-matrix-go/internal/core/domain"
+	"service-matrix-go/internal/core/language"
+	"service-matrix-go/internal/core/ports"
+	"service-matrix-go/internal/infrastructure/kvstore"
+	"service-matrix-go/internal/infrastructure/memory"
+	"service-matrix-go/internal/infrastructure/storage"
+)
+
+// backend opens one kind of repository pair in a temp directory. reopen, if
+// the backend persists anything, closes the repositories and opens them again.
+type backend struct {
+	name string
+	open func(t *testing.T) (words ports.WordRepository, subs ports.SubmissionRepository, reopen func() (ports.WordRepository, ports.SubmissionRepository))
+}
+
+var backends = []backend{
+	{"file", func(t *testing.T) (ports.WordRepository, ports.SubmissionRepository, func() (ports.WordRepository, ports.SubmissionRepository)) {
+		fh := storage.NewFileHelper(t.TempDir())
+		languages := language.NewRegistry()
+		open := func() (ports.WordRepository, ports.SubmissionRepository) {
+			return storage.NewFileWordRepository(fh, languages, nil), storage.NewFileSubmissionRepository(fh, "data", "submissions.jsonl")
+		}
+		words, subs := open()
+		return words, subs, open
+	}},
+	{"memory", func(t *testing.T) (ports.WordRepository, ports.SubmissionRepository, func() (ports.WordRepository, ports.SubmissionRepository)) {
+		return memory.NewWordRepository(), memory.NewSubmissionRepository(), nil
+	}},
+	{"kv", func(t *testing.T) (ports.WordRepository, ports.SubmissionRepository, func() (ports.WordRepository, ports.SubmissionRepository)) {
+		path := filepath.Join(t.TempDir(), "words.db")
+		var store *kvstore.Store
+		open := func() (ports.WordRepository, ports.SubmissionRepository) {
+			if store != nil {
+				store.Close()
+			}
+			var err error
+			if store, err = kvstore.Open(path); err != nil {
+				t.Fatal(err)
+			}
+			return kvstore.NewWordRepository(store), kvstore.NewSubmissionRepository(store)
+		}
+		t.Cleanup(func() { store.Close() })
+		words, subs := open()
+		return words, subs, open
+	}},
+}
+
+var (
+	ruInclude = ports.ListRef{Language: "ru", Name: ports.ListInclude}
+	ruExclude = ports.ListRef{Language: "ru", Name: ports.ListExclude}
+	ruMerged  = ports.ListRef{Language: "ru", Name: ports.ListMerged}
+)
+
+func wantList(t *testing.T, repo ports.WordRepository, ref ports.ListRef, want ...string) {
+	t.Helper()
+	got, err := repo.List(context.Background(), ref)
+	if err != nil {
+		t.Fatalf("List(%s): %v", ref.Name, err)
+	}
+	if len(got) != len(want) || (len(want) > 0 && !slices.Equal(got, want)) {
+		t.Errorf("List(%s) = %q, want %q", ref.Name, got, want)
+	}
+}
+
+func TestWordRepositoryContract(t *testing.T) {
+	ctx := context.Background()
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			repo, _, reopen := b.open(t)
+
+			wantList(t, repo, ruInclude)
+
+			added, err := repo.Add(ctx, ruInclude, []string{"кот", "дом", "кот"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := []string{"кот", "дом"}; !slices.Equal(added, want) {
+				t.Errorf("Add returned %q, want %q", added, want)
+			}
+			added, err = repo.Add(ctx, ruInclude, []string{"дом", "лес", "мышь"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := []string{"лес", "мышь"}; !slices.Equal(added, want) {
+				t.Errorf("second Add returned %q, want %q", added, want)
+			}
+			wantList(t, repo, ruInclude, "кот", "дом", "лес", "мышь")
+
+			for word, want := range map[string]bool{"лес": true, "сад": false} {
+				if got, err := repo.Contains(ctx, ruInclude, word); err != nil || got != want {
+					t.Errorf("Contains(%s) = %v, %v; want %v", word, got, err, want)
+				}
+			}
+
+			removed, err := repo.Remove(ctx, ruInclude, []string{"дом", "сад"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := []string{"дом"}; !slices.Equal(removed, want) {
+				t.Errorf("Remove returned %q, want %q", removed, want)
+			}
+			wantList(t, repo, ruInclude, "кот", "лес", "мышь")
+
+			// Lists don't share words
+			if _, err := repo.Add(ctx, ruExclude, []string{"кот"}); err != nil {
+				t.Fatal(err)
+			}
+			if err := repo.Replace(ctx, ruMerged, []string{"сад", "пруд"}); err != nil {
+				t.Fatal(err)
+			}
+			if err := repo.Replace(ctx, ruMerged, []string{"пруд", "луг"}); err != nil {
+				t.Fatal(err)
+			}
+			wantList(t, repo, ruExclude, "кот")
+			wantList(t, repo, ruMerged, "пруд", "луг")
+			wantList(t, repo, ruInclude, "кот", "лес", "мышь")
+
+			if reopen == nil {
+				return
+			}
+			repo, _ = reopen()
+			wantList(t, repo, ruInclude, "кот", "лес", "мышь")
+			wantList(t, repo, ruExclude, "кот")
+			wantList(t, repo, ruMerged, "пруд", "луг")
+
+			// Words added after reopening go after the old ones
+			if _, err := repo.Add(ctx, ruInclude, []string{"дом"}); err != nil {
+				t.Fatal(err)
+			}
+			wantList(t, repo, ruInclude, "кот", "лес", "мышь", "дом")
+		})
+	}
+}
+
+func TestSubmissionRepositoryContract(t *testing.T) {
+	ctx := context.Background()
+	submitted := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			_, repo, reopen := b.open(t)
+
+			if _, err := repo.Get(ctx, "missing"); !errors.Is(err, domain.ErrNotFound) {
+				t.Errorf("Get(missing): %v, want ErrNotFound", err)
+			}
+			if subs, err := repo.List(ctx); err != nil || len(subs) != 0 {
+				t.Errorf("List of an empty queue = %v, %v", subs, err)
+			}
+
+			for i, word := range []string{"кот", "дом", "лес"} {
+				sub := domain.Submission{
+					ID:          string(rune('a' + i)),
+					Word:        word,
+					Language:    "ru",
+					Include:     true,
+					Status:      domain.SubmissionPending,
+					Submitter:   "bot",
+					Voters:      []string{"bot"},
+					Votes:       1,
+					SubmittedAt: submitted.Add(time.Duration(i) * time.Minute),
+				}
+				if err := repo.Save(ctx, sub); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// Saving an existing ID replaces it in place
+			sub, err := repo.Get(ctx, "b")
+			if err != nil {
+				t.Fatal(err)
+			}
+			sub.Status = domain.SubmissionApproved
+			sub.Voters = append(sub.Voters, "frontend")
+			sub.Votes = 2
+			if err := repo.Save(ctx, sub); err != nil {
+				t.Fatal(err)
+			}
+
+			check := func(repo ports.SubmissionRepository) {
+				t.Helper()
+				subs, err := repo.List(ctx)
+				if err != nil {
+					t.Fatal(err)
+				}
+				var words []string
+				for _, sub := range subs {
+					words = append(words, sub.Word)
+				}
+				if want := []string{"кот", "дом", "лес"}; !slices.Equal(words, want) {
+					t.Errorf("List returned %q, want %q in the order saved", words, want)
+				}
+
+				got, err := repo.Get(ctx, "b")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got.Status != domain.SubmissionApproved || got.Votes != 2 || !slices.Equal(got.Voters, []string{"bot", "frontend"}) {
+					t.Errorf("Get(b) = %+v, want the approved version", got)
+				}
+				if !got.SubmittedAt.Equal(submitted.Add(time.Minute)) {
+					t.Errorf("Get(b) submitted at %v, want %v", got.SubmittedAt, submitted.Add(time.Minute))
+				}
+			}
+			check(repo)
+			if reopen != nil {
+				_, repo = reopen()
+				check(repo)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+// Package wordstore opens the word list and moderation queue backend the
+// server and the command line tools share
+package wordstore
+
+import (
+	"fmt"
+
+	"service-matrix-go/internal/config"
+	"service-matrix-go/internal/core/language"
+	"service-matrix-go/internal/core/ports"
+	"service-matrix-go/internal/infrastructure/kvstore"
+	"service-matrix-go/internal/infrastructure/memory"
+	"service-matrix-go/internal/infrastructure/storage"
+)
+
+// Open picks the backend from cfg.Store: "file" keeps the flat files,
+// "memory" keeps nothing across restarts and "kv" uses a single-file store.
+func Open(cfg config.Config, fh *storage.FileHelper, languages *language.Registry) (ports.WordRepository, ports.SubmissionRepository, error) {
+	switch cfg.Store.Backend {
+	case "file":
+		return storage.NewFileWordRepository(fh, languages, cfg.ListFiles()),
+			storage.NewFileSubmissionRepository(fh, cfg.DataDir, cfg.Files.Submissions), nil
+	case "memory":
+		return memory.NewWordRepository(), memory.NewSubmissionRepository(), nil
+	case "kv":
+		store, err := kvstore.Open(cfg.StorePath())
+		if err != nil {
+			return nil, nil, err
+		}
+		return kvstore.NewWordRepository(store), kvstore.NewSubmissionRepository(store), nil
+	default:
+		return nil, nil, fmt.Errorf("unknown word store %q", cfg.Store.Backend)
+	}
+}
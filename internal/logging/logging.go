@@ -0,0 +1,74 @@
+// Package logging sets up the service's structured logger. Records logged
+// with a request's context carry its request ID, client and API key.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"service-matrix-go/internal/core/requestinfo"
+)
+
+// Formats the logger can write
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// ParseLevel accepts debug, info, warn or error
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(s)); err != nil {
+		return 0, fmt.Errorf("unknown log level %q, want debug, info, warn or error", s)
+	}
+	return level, nil
+}
+
+// New returns a logger writing records at level and above to w, as text or JSON
+func New(w io.Writer, level, format string) (*slog.Logger, error) {
+	lvl, err := ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	opts := &slog.HandlerOptions{Level: lvl}
+	var h slog.Handler
+	switch strings.ToLower(format) {
+	case FormatText:
+		h = slog.NewTextHandler(w, opts)
+	case FormatJSON:
+		h = slog.NewJSONHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("unknown log format %q, want text or json", format)
+	}
+	return slog.New(contextHandler{h}), nil
+}
+
+// contextHandler adds the request info of the record's context
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	info := requestinfo.FromContext(ctx)
+	if info.RequestID != "" {
+		r.AddAttrs(slog.String("request_id", info.RequestID))
+	}
+	if info.Client != "" {
+		r.AddAttrs(slog.String("client", info.Client))
+	}
+	if info.Key != "" {
+		r.AddAttrs(slog.String("key", info.Key))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,283 @@
+// Package metrics is a small Prometheus client: counters, gauges and
+// histograms with labels, written out in the text exposition format
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets suit request latencies in seconds
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Registry holds metrics in the order they were created
+type Registry struct {
+	mu      sync.Mutex
+	metrics []collector
+	names   map[string]bool
+}
+
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{names: make(map[string]bool)}
+}
+
+// collector is one metric family
+type collector interface {
+	write(w *bufio.Writer)
+}
+
+func (r *Registry) register(name string, c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.names[name] {
+		panic("metrics: " + name + " registered twice")
+	}
+	r.names[name] = true
+	r.metrics = append(r.metrics, c)
+}
+
+// WriteText writes every metric in the Prometheus text format
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	metrics := append([]collector(nil), r.metrics...)
+	r.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	for _, m := range metrics {
+		m.write(bw)
+	}
+	return bw.Flush()
+}
+
+// Handler serves the registry for Prometheus to scrape
+func (r *Registry) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.WriteText(w)
+	}
+}
+
+// family is what every metric type shares: its name, help and labelled series
+type family struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+type series struct {
+	labelValues []string
+	value       float64
+	// buckets, sum and count are only used by histograms
+	buckets []uint64
+	sum     float64
+	count   uint64
+}
+
+func newFamily(name, help, kind string, labels []string) *family {
+	return &family{name: name, help: help, kind: kind, labels: labels, series: make(map[string]*series)}
+}
+
+// get returns the series for labelValues, creating it; the caller holds f.mu
+func (f *family) get(labelValues []string, buckets int) *series {
+	if len(labelValues) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: %s wants %d label values, got %d", f.name, len(f.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labelValues: append([]string(nil), labelValues...), buckets: make([]uint64, buckets)}
+		f.series[key] = s
+	}
+	return s
+}
+
+// sorted returns the series ordered by label values, so output is stable
+func (f *family) sorted() []*series {
+	list := make([]*series, 0, len(f.series))
+	for _, s := range f.series {
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i].labelValues, list[j].labelValues
+		for k := range a {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return false
+	})
+	return list
+}
+
+func (f *family) writeHeader(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", f.name, escapeHelp(f.help), f.name, f.kind)
+}
+
+// labelString formats the labels of s, plus an optional extra pair
+func (f *family) labelString(s *series, extraName, extraValue string) string {
+	var parts []string
+	for i, name := range f.labels {
+		parts = append(parts, name+`="`+escapeLabel(s.labelValues[i])+`"`)
+	}
+	if extraName != "" {
+		parts = append(parts, extraName+`="`+escapeLabel(extraValue)+`"`)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// Counter only goes up
+type Counter struct {
+	f *family
+}
+
+// NewCounter registers a counter with the given label names
+func (r *Registry) NewCounter(name, help string, labels ...string) *Counter {
+	c := &Counter{f: newFamily(name, help, "counter", labels)}
+	r.register(name, c)
+	return c
+}
+
+// Inc adds one to the series with labelValues
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds v, which must not be negative, to the series with labelValues
+func (c *Counter) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		panic("metrics: counter " + c.f.name + " cannot decrease")
+	}
+	c.f.mu.Lock()
+	c.f.get(labelValues, 0).value += v
+	c.f.mu.Unlock()
+}
+
+func (c *Counter) write(w *bufio.Writer) {
+	writeValues(w, c.f)
+}
+
+// Gauge goes up and down
+type Gauge struct {
+	f *family
+}
+
+// NewGauge registers a gauge with the given label names
+func (r *Registry) NewGauge(name, help string, labels ...string) *Gauge {
+	g := &Gauge{f: newFamily(name, help, "gauge", labels)}
+	r.register(name, g)
+	return g
+}
+
+// Set sets the series with labelValues to v
+func (g *Gauge) Set(v float64, labelValues ...string) {
+	g.f.mu.Lock()
+	g.f.get(labelValues, 0).value = v
+	g.f.mu.Unlock()
+}
+
+func (g *Gauge) write(w *bufio.Writer) {
+	writeValues(w, g.f)
+}
+
+func writeValues(w *bufio.Writer, f *family) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.writeHeader(w)
+	for _, s := range f.sorted() {
+		fmt.Fprintf(w, "%s%s %s\n", f.name, f.labelString(s, "", ""), formatFloat(s.value))
+	}
+}
+
+// gaugeFunc reports a value computed at scrape time
+type gaugeFunc struct {
+	f     *family
+	value func() float64
+}
+
+// NewGaugeFunc registers an unlabelled gauge whose value is read at scrape time
+func (r *Registry) NewGaugeFunc(name, help string, value func() float64) {
+	r.register(name, &gaugeFunc{f: newFamily(name, help, "gauge", nil), value: value})
+}
+
+func (g *gaugeFunc) write(w *bufio.Writer) {
+	g.f.writeHeader(w)
+	fmt.Fprintf(w, "%s %s\n", g.f.name, formatFloat(g.value()))
+}
+
+// Histogram counts observations into buckets
+type Histogram struct {
+	f      *family
+	bounds []float64
+}
+
+// NewHistogram registers a histogram with the given upper bucket bounds,
+// in increasing order, and label names
+func (r *Registry) NewHistogram(name, help string, bounds []float64, labels ...string) *Histogram {
+	if !sort.Float64sAreSorted(bounds) {
+		panic("metrics: buckets of " + name + " are not sorted")
+	}
+	h := &Histogram{f: newFamily(name, help, "histogram", labels), bounds: bounds}
+	r.register(name, h)
+	return h
+}
+
+// Observe records v in the series with labelValues
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	h.f.mu.Lock()
+	defer h.f.mu.Unlock()
+	s := h.f.get(labelValues, len(h.bounds))
+	for i, bound := range h.bounds {
+		if v <= bound {
+			s.buckets[i]++
+		}
+	}
+	s.sum += v
+	s.count++
+}
+
+func (h *Histogram) write(w *bufio.Writer) {
+	h.f.mu.Lock()
+	defer h.f.mu.Unlock()
+	h.f.writeHeader(w)
+	for _, s := range h.f.sorted() {
+		for i, bound := range h.bounds {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.f.name, h.f.labelString(s, "le", formatFloat(bound)), s.buckets[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.f.name, h.f.labelString(s, "le", "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.f.name, h.f.labelString(s, "", ""), formatFloat(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.f.name, h.f.labelString(s, "", ""), s.count)
+	}
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
@@ -0,0 +1,174 @@
+package metrics
+
+import (
+	"math"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func scrape(t *testing.T, reg *Registry) string {
+	t.Helper()
+	var out strings.Builder
+	if err := reg.WriteText(&out); err != nil {
+		t.Fatal(err)
+	}
+	return out.String()
+}
+
+func wantText(t *testing.T, got, want string) {
+	t.Helper()
+	if got != want {
+		t.Errorf("exposition differs\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestExposition(t *testing.T) {
+	reg := NewRegistry()
+	requests := reg.NewCounter("http_requests_total", "Requests served.", "route", "status")
+	entries := reg.NewGauge("dictionary_entries", "Entries loaded.", "language")
+	reg.NewGaugeFunc("goroutines", "Running goroutines.", func() float64 { return 7 })
+	reg.NewCounter("unused_total", "Never incremented.")
+
+	// Series come out sorted by label values, not in the order first seen
+	requests.Inc("/search", "200")
+	requests.Add(2, "/lookup", "404")
+	requests.Inc("/search", "200")
+	requests.Inc("/lookup", "200")
+	entries.Set(159790, "ru")
+	entries.Set(1.5, "en")
+	entries.Set(0.25, "en")
+
+	wantText(t, scrape(t, reg), `# HELP http_requests_total Requests served.
+# TYPE http_requests_total counter
+http_requests_total{route="/lookup",status="200"} 1
+http_requests_total{route="/lookup",status="404"} 2
+http_requests_total{route="/search",status="200"} 2
+# HELP dictionary_entries Entries loaded.
+# TYPE dictionary_entries gauge
+dictionary_entries{language="en"} 0.25
+dictionary_entries{language="ru"} 159790
+# HELP goroutines Running goroutines.
+# TYPE goroutines gauge
+goroutines 7
+# HELP unused_total Never incremented.
+# TYPE unused_total counter
+`)
+}
+
+func TestHistogramBuckets(t *testing.T) {
+	reg := NewRegistry()
+	h := reg.NewHistogram("search_seconds", "Search time.", []float64{0.1, 1, 10}, "board")
+	// A value on a bound falls in that bucket; buckets are cumulative
+	for _, v := range []float64{0.05, 0.1, 0.5, 1, 3, 20} {
+		h.Observe(v, "small")
+	}
+	h.Observe(2, "large")
+
+	wantText(t, scrape(t, reg), `# HELP search_seconds Search time.
+# TYPE search_seconds histogram
+search_seconds_bucket{board="large",le="0.1"} 0
+search_seconds_bucket{board="large",le="1"} 0
+search_seconds_bucket{board="large",le="10"} 1
+search_seconds_bucket{board="large",le="+Inf"} 1
+search_seconds_sum{board="large"} 2
+search_seconds_count{board="large"} 1
+search_seconds_bucket{board="small",le="0.1"} 2
+search_seconds_bucket{board="small",le="1"} 4
+search_seconds_bucket{board="small",le="10"} 5
+search_seconds_bucket{board="small",le="+Inf"} 6
+search_seconds_sum{board="small"} 24.65
+search_seconds_count{board="small"} 6
+`)
+}
+
+func TestUnlabelledHistogram(t *testing.T) {
+	reg := NewRegistry()
+	h := reg.NewHistogram("found", "Words found.", []float64{0, 5})
+	h.Observe(0)
+	h.Observe(7)
+
+	wantText(t, scrape(t, reg), `# HELP found Words found.
+# TYPE found histogram
+found_bucket{le="0"} 1
+found_bucket{le="5"} 1
+found_bucket{le="+Inf"} 2
+found_sum 7
+found_count 2
+`)
+}
+
+func TestEscaping(t *testing.T) {
+	reg := NewRegistry()
+	c := reg.NewCounter("escaped_total", "Help with a \\ backslash,\na newline and \"quotes\".", "value")
+	c.Inc(`back\slash`)
+	c.Inc("new\nline")
+	c.Inc(`"quoted"`)
+	c.Inc("ёж")
+
+	wantText(t, scrape(t, reg), `# HELP escaped_total Help with a \\ backslash,\na newline and "quotes".
+# TYPE escaped_total counter
+escaped_total{value="\"quoted\""} 1
+escaped_total{value="back\\slash"} 1
+escaped_total{value="new\nline"} 1
+escaped_total{value="ёж"} 1
+`)
+}
+
+func TestFormatFloat(t *testing.T) {
+	tests := map[float64]string{
+		0:            "0",
+		1:            "1",
+		0.005:        "0.005",
+		2.5:          "2.5",
+		1e21:         "1e+21",
+		math.Inf(1):  "+Inf",
+		math.Inf(-1): "-Inf",
+		math.NaN():   "NaN",
+	}
+	for v, want := range tests {
+		if got := formatFloat(v); got != want {
+			t.Errorf("formatFloat(%v) = %q, want %q", v, got, want)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	reg := NewRegistry()
+	reg.NewCounter("hits_total", "Hits.").Inc()
+
+	w := httptest.NewRecorder()
+	reg.Handler()(w, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := w.Header().Get("Content-Type"); ct != "text/plain; version=0.0.4; charset=utf-8" {
+		t.Errorf("Content-Type %q", ct)
+	}
+	wantText(t, w.Body.String(), "# HELP hits_total Hits.\n# TYPE hits_total counter\nhits_total 1\n")
+}
+
+func TestMisuse(t *testing.T) {
+	tests := map[string]func(reg *Registry){
+		"registered twice": func(reg *Registry) {
+			reg.NewCounter("twice_total", "")
+			reg.NewGauge("twice_total", "")
+		},
+		"wrong label count": func(reg *Registry) {
+			reg.NewCounter("labelled_total", "", "a", "b").Inc("only a")
+		},
+		"counter decreases": func(reg *Registry) {
+			reg.NewCounter("down_total", "").Add(-1)
+		},
+		"unsorted buckets": func(reg *Registry) {
+			reg.NewHistogram("unsorted", "", []float64{1, 0.5})
+		},
+	}
+	for name, misuse := range tests {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Error("no panic")
+				}
+			}()
+			misuse(NewRegistry())
+		})
+	}
+}
@@ -0,0 +1,92 @@
+// Package ratelimit keeps a token bucket per client
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Rate is how fast a bucket refills and how many tokens it holds.
+// A zero PerSecond means no limit.
+type Rate struct {
+	PerSecond float64 `json:"perSecond"`
+	Burst     int     `json:"burst"`
+}
+
+// Unlimited reports whether r lets everything through
+func (r Rate) Unlimited() bool {
+	return r.PerSecond <= 0
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter holds one bucket per client key. Buckets that have refilled
+// completely are forgotten, so idle clients cost nothing.
+type Limiter struct {
+	rate Rate
+	now  func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewLimiter returns a limiter granting each client rate
+func NewLimiter(rate Rate) *Limiter {
+	return NewLimiterWithClock(rate, time.Now)
+}
+
+// NewLimiterWithClock returns a limiter that reads the time from now
+func NewLimiterWithClock(rate Rate, now func() time.Time) *Limiter {
+	if rate.Burst < 1 {
+		rate.Burst = 1
+	}
+	return &Limiter{rate: rate, now: now, buckets: make(map[string]*bucket)}
+}
+
+// Allow takes a token from client's bucket. When the bucket is empty it
+// returns false and how long until the next token.
+func (l *Limiter) Allow(client string) (bool, time.Duration) {
+	if l.rate.Unlimited() {
+		return true, 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &bucket{tokens: float64(l.rate.Burst), last: now}
+		l.buckets[client] = b
+	}
+	b.tokens = l.refill(b, now)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	wait := (1 - b.tokens) / l.rate.PerSecond
+	return false, time.Duration(math.Ceil(wait * float64(time.Second)))
+}
+
+func (l *Limiter) refill(b *bucket, now time.Time) float64 {
+	return math.Min(float64(l.rate.Burst), b.tokens+now.Sub(b.last).Seconds()*l.rate.PerSecond)
+}
+
+// sweep drops full buckets about once a minute
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < time.Minute {
+		return
+	}
+	l.lastSweep = now
+	for client, b := range l.buckets {
+		if l.refill(b, now) >= float64(l.rate.Burst) {
+			delete(l.buckets, client)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+// clock is a fake time source moved on by hand
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time { return c.t }
+
+func (c *clock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newClock() *clock {
+	return &clock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func TestAllowSpendsTheBurst(t *testing.T) {
+	l := NewLimiterWithClock(Rate{PerSecond: 2, Burst: 3}, newClock().now)
+	for i := range 3 {
+		if ok, wait := l.Allow("a"); !ok || wait != 0 {
+			t.Fatalf("request %d: %v, %v; want allowed within the burst", i+1, ok, wait)
+		}
+	}
+	if ok, wait := l.Allow("a"); ok || wait != 500*time.Millisecond {
+		t.Errorf("request past the burst: %v, %v; want refused for 500ms", ok, wait)
+	}
+
+	// Clients have their own buckets
+	if ok, _ := l.Allow("b"); !ok {
+		t.Error("another client was refused")
+	}
+}
+
+func TestAllowRefills(t *testing.T) {
+	c := newClock()
+	l := NewLimiterWithClock(Rate{PerSecond: 2, Burst: 3}, c.now)
+	for range 3 {
+		l.Allow("a")
+	}
+
+	c.advance(200 * time.Millisecond)
+	if ok, wait := l.Allow("a"); ok || wait != 300*time.Millisecond {
+		t.Errorf("after 200ms: %v, %v; want refused for the remaining 300ms", ok, wait)
+	}
+	c.advance(300 * time.Millisecond)
+	if ok, _ := l.Allow("a"); !ok {
+		t.Error("refused once a token had refilled")
+	}
+	if ok, _ := l.Allow("a"); ok {
+		t.Error("allowed a second request on one refilled token")
+	}
+
+	// A long wait refills no more than the burst
+	c.advance(time.Hour)
+	allowed := 0
+	for range 10 {
+		if ok, _ := l.Allow("a"); ok {
+			allowed++
+		}
+	}
+	if allowed != 3 {
+		t.Errorf("allowed %d requests after an hour, want the burst of 3", allowed)
+	}
+}
+
+func TestAllowRoundsTheWaitUp(t *testing.T) {
+	l := NewLimiterWithClock(Rate{PerSecond: 3}, newClock().now)
+	l.Allow("a")
+	if _, wait := l.Allow("a"); wait != 333333334*time.Nanosecond {
+		t.Errorf("wait %v, want a third of a second rounded up", wait)
+	}
+}
+
+func TestUnlimited(t *testing.T) {
+	l := NewLimiterWithClock(Rate{}, newClock().now)
+	for range 100 {
+		if ok, _ := l.Allow("a"); !ok {
+			t.Fatal("a zero rate refused a request")
+		}
+	}
+	if len(l.buckets) != 0 {
+		t.Errorf("an unlimited limiter keeps %d buckets", len(l.buckets))
+	}
+}
+
+func TestSweepForgetsFullBuckets(t *testing.T) {
+	c := newClock()
+	l := NewLimiterWithClock(Rate{PerSecond: 1, Burst: 2}, c.now)
+	l.Allow("idle")
+	c.advance(time.Minute)
+	l.Allow("busy")
+	if _, ok := l.buckets["idle"]; ok {
+		t.Error("a refilled bucket survived the sweep")
+	}
+	if _, ok := l.buckets["busy"]; !ok {
+		t.Error("the bucket in use was swept")
+	}
+}